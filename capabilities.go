@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// capabilities describes what this build of the plugin supports. It is returned, as JSON, in the
+// value of every ConfigureResponse so config generators can be kept in sync with the plugin version.
+type capabilities struct {
+	Version    string          `json:"version"`
+	Resources  []string        `json:"resources"`
+	ConfigKeys []configKeyInfo `json:"config_keys"`
+}
+
+// configKeyInfo describes a configuration key. Default is empty for keys without a default.
+type configKeyInfo struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Default string `json:"default,omitempty"`
+}
+
+// configDefaults returns the default value of every config key that has one, as it would be written
+// in the configuration.
+func configDefaults() map[string]string {
+	sensitivePorts := make([]string, 0, len(defaultSensitivePorts))
+	for _, port := range defaultSensitivePorts {
+		sensitivePorts = append(sensitivePorts, strconv.Itoa(int(port)))
+	}
+	return map[string]string{
+		"assume_role_session_name":     internal.DefaultAssumeRoleSessionName,
+		"disable_ssl":                  "false",
+		"skip_default_vpc":             "false",
+		"discovery":                    internal.DiscoveryRegionScan,
+		"sensitive_ports":              strings.Join(sensitivePorts, ","),
+		"use_security_group_rules_api": "false",
+		"require_ingress_rules":        "false",
+		"skip_default_group":           "false",
+		"report_private_ips":           "false",
+		"flat_evidence":                "false",
+		"max_retries":                  strconv.Itoa(internal.DefaultMaxRetries),
+		"max_concurrency":              strconv.Itoa(internal.DefaultMaxConcurrency),
+		"eval_timeout":                 strconv.Itoa(int(internal.DefaultEvalTimeout.Seconds())),
+		"aws_request_timeout":          strconv.Itoa(int(internal.DefaultAWSRequestTimeout.Seconds())),
+		"evidence_batch_size":          strconv.Itoa(internal.DefaultEvidenceBatchSize),
+		"max_resources":                "0",
+		"dry_run":                      "false",
+		"aws_sdk_debug":                "false",
+		"resources":                    strings.Join(resourceNames(), ","),
+		"log_level":                    "info",
+	}
+}
+
+// pluginCapabilities returns the resource types and config keys supported by this build.
+func pluginCapabilities() capabilities {
+	version, _ := pluginVersion()
+	defaults := configDefaults()
+	keys := make([]configKeyInfo, 0, len(internal.ConfigKeys))
+	for _, key := range internal.ConfigKeys {
+		keys = append(keys, configKeyInfo{Name: key.Name, Type: string(key.Kind), Default: defaults[key.Name]})
+	}
+	return capabilities{
+		Version:    version,
+		Resources:  resourceNames(),
+		ConfigKeys: keys,
+	}
+}
+
+// capabilitiesJSON encodes pluginCapabilities for a ConfigureResponse.
+func capabilitiesJSON() ([]byte, error) {
+	return json.Marshal(pluginCapabilities())
+}
+
+// configKindPatterns are the patterns a value of each kind of key must match, as strconv.ParseBool
+// reads booleans. Integer and seconds keys are matched by rangePattern instead, from their bounds.
+var configKindPatterns = map[internal.ConfigKind]string{
+	internal.ConfigBoolean: `^(1|t|T|TRUE|true|True|0|f|F|FALSE|false|False)$`,
+}
+
+// configKindDescriptions describe the value expected by each kind of key.
+var configKindDescriptions = map[internal.ConfigKind]string{
+	internal.ConfigString:          "A string.",
+	internal.ConfigList:            "A comma-separated list.",
+	internal.ConfigPairs:           "Comma-separated key=value pairs.",
+	internal.ConfigBoolean:         "A boolean, true or false.",
+	internal.ConfigInteger:         "A non-negative integer.",
+	internal.ConfigPositiveInteger: "A positive integer.",
+	internal.ConfigPositiveNumber:  "A positive number.",
+	internal.ConfigSeconds:         "A positive number of seconds.",
+}
+
+// configSchema returns a JSON Schema of the plugin configuration, generated from internal.ConfigKeys
+// and configDefaults. All values are strings, constrained by their kind and by the values, pattern or
+// bounds their key is parsed with, and resources by resourceNames; checks that depend on other
+// keys or on AWS, such as role ARN partitions or profile names, are only made by Configure. Unknown
+// keys are rejected, as most are typos, although Configure only warns about them.
+func configSchema() map[string]any {
+	defaults := configDefaults()
+	properties := map[string]any{}
+	for _, key := range internal.ConfigKeys {
+		description := configKindDescriptions[key.Kind]
+		if constraint := key.Constraint(); constraint != "" {
+			description = strings.ToUpper(constraint[:1]) + constraint[1:] + "."
+		}
+		property := map[string]any{
+			"type":        "string",
+			"description": key.Description + " " + description,
+		}
+		switch {
+		case len(key.Values) > 0:
+			property["enum"] = key.Values
+		case key.Pattern != "":
+			property["pattern"] = key.Pattern
+		case key.Kind == internal.ConfigInteger || key.Kind == internal.ConfigPositiveInteger || key.Kind == internal.ConfigSeconds:
+			property["pattern"] = rangePattern(key.Minimum, key.Maximum)
+		case key.Name == "resources":
+			property["pattern"] = listPattern(resourceNames())
+		default:
+			if pattern, ok := configKindPatterns[key.Kind]; ok {
+				property["pattern"] = pattern
+			}
+		}
+		if value, ok := defaults[key.Name]; ok {
+			property["default"] = value
+		}
+		properties[key.Name] = property
+	}
+	return map[string]any{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"title":                "plugin-aws-networking-security configuration",
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// listPattern matches a comma-separated list of names, as Configure accepts for the resources key.
+// Empty entries are allowed, as internal.SplitList drops them.
+func listPattern(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, regexp.QuoteMeta(name))
+	}
+	entry := `\s*(` + strings.Join(quoted, "|") + `)?\s*`
+	return `^` + entry + `(,` + entry + `)*$`
+}
+
+// rangePattern matches the integers from minimum to maximum as internal.ParseConfig reads them, with
+// any leading zeros but no sign or whitespace. A maximum of zero is unbounded.
+func rangePattern(minimum, maximum int) string {
+	var alternatives []string
+	if maximum == 0 {
+		// Every number wider than minimum is in range.
+		width := len(strconv.Itoa(minimum))
+		alternatives = append(alternatives, fmt.Sprintf("[1-9][0-9]{%d,}", width))
+		maximum = pow10(width) - 1
+	}
+	for width := len(strconv.Itoa(minimum)); width <= len(strconv.Itoa(maximum)); width++ {
+		low := pow10(width - 1)
+		if width == 1 {
+			low = 0
+		}
+		alternatives = append(alternatives, widthPatterns(max(minimum, low), min(maximum, pow10(width)-1))...)
+	}
+	return `^0*(` + strings.Join(alternatives, "|") + `)$`
+}
+
+// widthPatterns matches the integers from low to high, which have the same number of digits. Each
+// pattern covers a run of blocks sharing their leading digits, e.g. 4[0-2][0-9]{3} for 40000 to 42999.
+func widthPatterns(low, high int) []string {
+	width := len(strconv.Itoa(low))
+	var patterns []string
+	for low <= high {
+		// The block is the widest run of numbers starting at low whose trailing digits are free.
+		block := 1
+		for block*10 <= pow10(width-1) && low%(block*10) == 0 && low+block*10-1 <= high {
+			block *= 10
+		}
+		lead := low / block
+		first := lead % 10
+		last := first
+		for last < 9 && low+(last-first+2)*block-1 <= high {
+			last++
+		}
+
+		pattern := ""
+		if lead >= 10 {
+			pattern = strconv.Itoa(lead / 10)
+		}
+		if first == last {
+			pattern += strconv.Itoa(first)
+		} else {
+			pattern += fmt.Sprintf("[%d-%d]", first, last)
+		}
+		if free := len(strconv.Itoa(block)) - 1; free == 1 {
+			pattern += "[0-9]"
+		} else if free > 1 {
+			pattern += fmt.Sprintf("[0-9]{%d}", free)
+		}
+		patterns = append(patterns, pattern)
+		low += (last - first + 1) * block
+	}
+	return patterns
+}
+
+func pow10(exponent int) int {
+	n := 1
+	for range exponent {
+		n *= 10
+	}
+	return n
+}
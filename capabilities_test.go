@@ -0,0 +1,110 @@
+package main
+
+import (
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"regexp"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestRangePattern(t *testing.T) {
+	tests := []struct {
+		minimum, maximum int
+	}{
+		{0, 0},
+		{1, 0},
+		{17, 0},
+		{1, 10},
+		{5, 123},
+		{99, 100},
+		{900, 43200},
+	}
+	for _, test := range tests {
+		pattern := regexp.MustCompile(rangePattern(test.minimum, test.maximum))
+		// Every number up to a thousand, the numbers around the bounds and a sample of larger ones.
+		numbers := []int{test.minimum - 1, test.minimum, test.minimum + 1, test.maximum - 1, test.maximum, test.maximum + 1}
+		for n := 0; n <= 1000; n++ {
+			numbers = append(numbers, n)
+		}
+		for n := 1000; n <= 100000; n += 97 {
+			numbers = append(numbers, n)
+		}
+		for _, n := range numbers {
+			if n < 0 {
+				continue
+			}
+			want := n >= test.minimum && (test.maximum == 0 || n <= test.maximum)
+			for _, value := range []string{strconv.Itoa(n), "00" + strconv.Itoa(n)} {
+				if got := pattern.MatchString(value); got != want {
+					t.Fatalf("rangePattern(%d, %d) = %s matches %q: %t, want %t", test.minimum, test.maximum, pattern, value, got, want)
+				}
+			}
+		}
+		for _, value := range []string{"", "-1", "+1", " 1", "1 ", "1.5", "ten"} {
+			if pattern.MatchString(value) {
+				t.Errorf("rangePattern(%d, %d) = %s matches %q", test.minimum, test.maximum, pattern, value)
+			}
+		}
+	}
+}
+
+func TestListPattern(t *testing.T) {
+	pattern := regexp.MustCompile(listPattern(resourceNames()))
+	for _, value := range []string{"", "nat-gateways", " nat-gateways , security-groups ", "nat-gateways,,subnets"} {
+		if !pattern.MatchString(value) {
+			t.Errorf("%s does not match %q", pattern, value)
+		}
+	}
+	for _, value := range []string{"nat-gateway", "nat-gateways subnets", "nat-gateways,unknown"} {
+		if pattern.MatchString(value) {
+			t.Errorf("%s matches %q", pattern, value)
+		}
+	}
+}
+
+// TestConfigSchemaMatchesParseConfig checks the schema accepts exactly the scalar values ParseConfig
+// accepts: integers, numbers, booleans, choices and patterned strings. An empty value is the same as
+// leaving the key out, so it is not compared.
+func TestConfigSchemaMatchesParseConfig(t *testing.T) {
+	properties := configSchema()["properties"].(map[string]any)
+	values := []string{
+		"0", "1", "2", "05", "899", "900", "43200", "43201", "-1", "+5", " 5", "5 ", " ", "1.5", ".5", "1.", "1e3", "0x10", "Inf",
+		"x", "ab", "a b", "true", " true", "T", "yes",
+		"info", "INFO", " info", "info ", "region-scan", "region-scan ", "other",
+	}
+	for _, key := range internal.ConfigKeys {
+		property := properties[key.Name].(map[string]any)
+		if property["description"] == "" {
+			t.Errorf("%s has no description", key.Name)
+		}
+		scalar := key.Kind != internal.ConfigString && key.Kind != internal.ConfigList && key.Kind != internal.ConfigPairs
+		if !scalar && len(key.Values) == 0 && key.Pattern == "" {
+			continue
+		}
+		for _, value := range values {
+			raw := map[string]string{key.Name: value}
+			// Keys that depend on another key are given it, as the schema does not check dependencies.
+			switch key.Name {
+			case "discovery":
+				raw["tag_filters"] = "team=network"
+			case "disable_ssl":
+				raw["endpoint_url"] = "https://localhost:4566"
+			}
+			_, err := internal.ParseConfig(raw)
+			if got, want := schemaAccepts(property, value), err == nil; got != want {
+				t.Errorf("schema of %s accepts %q: %t, ParseConfig accepts it: %t (%v)", key.Name, value, got, want, err)
+			}
+		}
+	}
+}
+
+func schemaAccepts(property map[string]any, value string) bool {
+	if enum, ok := property["enum"].([]string); ok {
+		return slices.Contains(enum, value)
+	}
+	if pattern, ok := property["pattern"].(string); ok {
+		return regexp.MustCompile(pattern).MatchString(value)
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var clientVpnComponent = &proto.Component{
+	Identifier:  "common-components/aws-client-vpn",
+	Type:        "service",
+	Title:       "AWS Client VPN Endpoints",
+	Description: "AWS Client VPN endpoints provide managed, OpenVPN-based remote access to VPCs and on-premises networks. Each endpoint defines how users authenticate, whether connections are logged, and whether only VPC-bound traffic or all traffic is sent through the tunnel.",
+	Purpose:     "To enforce authenticated, logged and fully tunnelled remote access into private networks, supporting remote access and audit logging controls.",
+}
+
+func (l *CompliancePlugin) evalClientVpnEndpoints(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	found := 0
+	for endpoint, err := range getClientVpnEndpoints(ctx, scan.client, &ec2.DescribeClientVpnEndpointsInput{}) {
+		if err != nil {
+			l.logger.Error("unable to get Client VPN endpoint", "region", scan.region, "error", err)
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+			continue
+		}
+		// DescribeClientVpnEndpoints cannot filter by VPC, so vpc_ids is applied here.
+		vpcID := aws.ToString(endpoint.VpcId)
+		if (len(l.config.VpcIDs) > 0 && !slices.Contains(l.config.VpcIDs, vpcID)) || scan.excludesVpc(vpcID) {
+			continue
+		}
+
+		endpointID := aws.ToString(endpoint.ClientVpnEndpointId)
+		authenticationTypes := make([]string, 0, len(endpoint.AuthenticationOptions))
+		for _, authentication := range endpoint.AuthenticationOptions {
+			authenticationTypes = append(authenticationTypes, string(authentication.Type))
+		}
+		connectionLogEnabled := endpoint.ConnectionLogOptions != nil && aws.ToBool(endpoint.ConnectionLogOptions.Enabled)
+		status := ""
+		if endpoint.Status != nil {
+			status = string(endpoint.Status.Code)
+		}
+		if l.excludesState(scan, "client-vpn-endpoints", "client-vpn-endpoint", status) {
+			continue
+		}
+		found++
+
+		labels := internal.MergeMaps(scan.labels, l.tagLabels(endpoint.Tags), map[string]string{
+			"type":                   "client-vpn-endpoint",
+			"client-vpn-endpoint-id": endpointID,
+			"_vpc-id":                vpcID,
+		})
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-client-vpn-endpoint/%s", endpointID),
+			Type:       "network",
+			Title:      fmt.Sprintf("AWS Client VPN Endpoint [%s]", endpointID),
+			Props: slices.Concat([]*proto.Property{
+				{
+					Name:  "client-vpn-endpoint-id",
+					Value: endpointID,
+				},
+				{
+					Name:  "vpc-id",
+					Value: vpcID,
+				},
+				{
+					Name:  "status",
+					Value: status,
+				},
+				{
+					Name:  "authentication-type",
+					Value: strings.Join(authenticationTypes, ","),
+				},
+				{
+					Name:  "connection-log-enabled",
+					Value: strconv.FormatBool(connectionLogEnabled),
+				},
+				{
+					Name:  "split-tunnel",
+					Value: strconv.FormatBool(aws.ToBool(endpoint.SplitTunnel)),
+				},
+				{
+					Name:  "transport-protocol",
+					Value: string(endpoint.TransportProtocol),
+				},
+				{
+					Name:  "security-group-ids",
+					Value: strings.Join(endpoint.SecurityGroupIds, ","),
+				},
+				{
+					Name:  "client-cidr-block",
+					Value: aws.ToString(endpoint.ClientCidrBlock),
+				},
+			}, tagProperties(endpoint.Tags)),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, clientVpnComponent, inventory, collectionActivities("Client VPN endpoint", "DescribeClientVpnEndpoints"), endpoint); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	if found == 0 && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "client-vpn-endpoint", "Client VPN endpoints", collectionActivities("Client VPN endpoint", "DescribeClientVpnEndpoints"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+func getClientVpnEndpoints(ctx context.Context, client NetworkingAPI, input *ec2.DescribeClientVpnEndpointsInput) iter.Seq2[types.ClientVpnEndpoint, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeClientVpnEndpointsOutput, *ec2.Options] {
+		return ec2.NewDescribeClientVpnEndpointsPaginator(client, input)
+	}, func(page *ec2.DescribeClientVpnEndpointsOutput) []types.ClientVpnEndpoint {
+		return page.ClientVpnEndpoints
+	})
+}
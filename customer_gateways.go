@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"slices"
+)
+
+var customerGatewayComponent = &proto.Component{
+	Identifier:  "common-components/aws-customer-gateway",
+	Type:        "service",
+	Title:       "AWS Site-to-Site VPN Customer Gateways",
+	Description: "AWS customer gateways describe the on-premises end of Site-to-Site VPN connections: the public IP address of the on-premises device and, for dynamic routing, its BGP autonomous system number.",
+	Purpose:     "To ensure VPN tunnels only terminate on approved on-premises devices and advertise routes from expected autonomous systems.",
+}
+
+func (l *CompliancePlugin) evalCustomerGateways(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	gateways, err := getCustomerGateways(ctx, scan.client, &ec2.DescribeCustomerGatewaysInput{})
+	if err != nil {
+		l.logger.Error("unable to get customer gateways", "region", scan.region, "error", err)
+		return err
+	}
+
+	found := 0
+	for _, gateway := range gateways {
+		if l.excludesState(scan, "customer-gateways", "customer-gateway", aws.ToString(gateway.State)) {
+			continue
+		}
+		found++
+		gatewayID := aws.ToString(gateway.CustomerGatewayId)
+		// BgpAsnExtended holds ASNs beyond the 2-byte range, in which case BgpAsn is not set.
+		bgpAsn := aws.ToString(gateway.BgpAsn)
+		if bgpAsn == "" {
+			bgpAsn = aws.ToString(gateway.BgpAsnExtended)
+		}
+
+		labels := internal.MergeMaps(scan.labels, l.tagLabels(gateway.Tags), map[string]string{
+			"type":                "customer-gateway",
+			"customer-gateway-id": gatewayID,
+		})
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-customer-gateway/%s", gatewayID),
+			Type:       "network",
+			Title:      fmt.Sprintf("AWS Customer Gateway [%s]", gatewayID),
+			Props: slices.Concat([]*proto.Property{
+				{
+					Name:  "customer-gateway-id",
+					Value: gatewayID,
+				},
+				{
+					Name:  "ip-address",
+					Value: aws.ToString(gateway.IpAddress),
+				},
+				{
+					Name:  "bgp-asn",
+					Value: bgpAsn,
+				},
+				{
+					Name:  "type",
+					Value: aws.ToString(gateway.Type),
+				},
+				{
+					Name:  "state",
+					Value: aws.ToString(gateway.State),
+				},
+				{
+					Name:  "device-name",
+					Value: aws.ToString(gateway.DeviceName),
+				},
+			}, tagProperties(gateway.Tags)),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, customerGatewayComponent, inventory, collectionActivities("customer gateway", "DescribeCustomerGateways"), gateway); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	if found == 0 && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "customer-gateway", "customer gateways", collectionActivities("customer gateway", "DescribeCustomerGateways"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+// getCustomerGateways returns every customer gateway in the region. DescribeCustomerGateways is not
+// paginated.
+func getCustomerGateways(ctx context.Context, client NetworkingAPI, input *ec2.DescribeCustomerGatewaysInput) ([]types.CustomerGateway, error) {
+	result, err := client.DescribeCustomerGateways(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return result.CustomerGateways, nil
+}
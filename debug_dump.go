@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dumpResource writes data, the resource passed to policies, as indented JSON to
+// `<debug_dump_dir>/<region>_<identifier>.json`, with the slashes of the inventory identifier replaced
+// by underscores. It does nothing unless debug_dump_dir is set. Dumping is a diagnostic aid, so
+// failures are logged and never fail the evaluation.
+func (l *CompliancePlugin) dumpResource(scan *regionScan, identifier string, data interface{}) {
+	if l.config.DebugDumpDir == "" {
+		return
+	}
+
+	if err := os.MkdirAll(l.config.DebugDumpDir, 0o755); err != nil {
+		l.logger.Warn("unable to create debug dump directory", "dir", l.config.DebugDumpDir, "error", err)
+		return
+	}
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		l.logger.Warn("unable to encode resource for debug dump", "resource", identifier, "error", err)
+		return
+	}
+
+	name := scan.region + "_" + strings.NewReplacer("/", "_", string(filepath.Separator), "_").Replace(identifier) + ".json"
+	path := filepath.Join(l.config.DebugDumpDir, name)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		l.logger.Warn("unable to write debug dump", "path", path, "error", err)
+		return
+	}
+	l.logger.Trace("Wrote debug dump", "path", path)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var dhcpOptionsComponent = &proto.Component{
+	Identifier:  "common-components/amazon-vpc-dhcp-options",
+	Type:        "service",
+	Title:       "Amazon VPC DHCP Option Sets",
+	Description: "Amazon VPC DHCP option sets configure the domain name, DNS servers, NTP servers and NetBIOS settings handed to resources in the VPCs they are associated with.",
+	Purpose:     "To ensure resources resolve names through approved DNS servers and synchronise time from approved sources, supporting reliable logging and protection against name resolution hijacking.",
+}
+
+// dhcpOptions is the policy input for a DHCP option set: the set as returned by the EC2 API, with the
+// in-scope VPCs using it alongside.
+type dhcpOptions struct {
+	types.DhcpOptions
+	VpcIds []string
+}
+
+func (l *CompliancePlugin) evalDhcpOptions(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	// Option sets are correlated with the VPCs described for the region. Without them, the VPCs using
+	// each set are not reported rather than reported as none.
+	vpcsByOptions := map[string][]string{}
+	for _, vpcID := range slices.Sorted(maps.Keys(scan.vpcs)) {
+		optionsID := aws.ToString(scan.vpcs[vpcID].DhcpOptionsId)
+		vpcsByOptions[optionsID] = append(vpcsByOptions[optionsID], vpcID)
+	}
+
+	found := 0
+	for options, err := range getDhcpOptions(ctx, scan.client, &ec2.DescribeDhcpOptionsInput{}) {
+		if err != nil {
+			l.logger.Error("unable to get DHCP options", "region", scan.region, "error", err)
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+			continue
+		}
+		found++
+
+		optionsID := aws.ToString(options.DhcpOptionsId)
+		data := dhcpOptions{
+			DhcpOptions: options,
+			VpcIds:      vpcsByOptions[optionsID],
+		}
+
+		labels := internal.MergeMaps(scan.labels, l.tagLabels(options.Tags), map[string]string{
+			"type":            "dhcp-options",
+			"dhcp-options-id": optionsID,
+		})
+
+		props := []*proto.Property{
+			{
+				Name:  "dhcp-options-id",
+				Value: optionsID,
+			},
+			{
+				Name:  "owner-id",
+				Value: aws.ToString(options.OwnerId),
+			},
+		}
+		if scan.vpcs != nil {
+			props = append(props,
+				&proto.Property{
+					Name:  "in-use",
+					Value: strconv.FormatBool(len(data.VpcIds) > 0),
+				},
+				&proto.Property{
+					Name:  "vpc-ids",
+					Value: strings.Join(data.VpcIds, ","),
+				},
+			)
+		}
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-dhcp-options/%s", optionsID),
+			Type:       "network",
+			Title:      fmt.Sprintf("Amazon VPC DHCP Option Set [%s]", optionsID),
+			Props:      slices.Concat(props, dhcpConfigurationProperties(options.DhcpConfigurations), tagProperties(options.Tags)),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, dhcpOptionsComponent, inventory, collectionActivities("DHCP option set", "DescribeDhcpOptions"), data); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	if found == 0 && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "dhcp-options", "DHCP option sets", collectionActivities("DHCP option set", "DescribeDhcpOptions"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+// dhcpConfigurationProperties reports each option, e.g. `domain-name-servers`, as a property named
+// after its key with its values comma-separated.
+func dhcpConfigurationProperties(configurations []types.DhcpConfiguration) []*proto.Property {
+	props := make([]*proto.Property, 0, len(configurations))
+	for _, configuration := range configurations {
+		values := make([]string, 0, len(configuration.Values))
+		for _, value := range configuration.Values {
+			values = append(values, aws.ToString(value.Value))
+		}
+		props = append(props, &proto.Property{
+			Name:  aws.ToString(configuration.Key),
+			Value: strings.Join(values, ","),
+		})
+	}
+	return props
+}
+
+func getDhcpOptions(ctx context.Context, client NetworkingAPI, input *ec2.DescribeDhcpOptionsInput) iter.Seq2[types.DhcpOptions, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeDhcpOptionsOutput, *ec2.Options] {
+		return ec2.NewDescribeDhcpOptionsPaginator(client, input)
+	}, func(page *ec2.DescribeDhcpOptionsOutput) []types.DhcpOptions {
+		return page.DhcpOptions
+	})
+}
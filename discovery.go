@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	taggingtypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// discoverSecurityGroups returns the IDs of the region's security groups tagged to match tag_filters,
+// as found by the Resource Groups Tagging API, see discovery. When group_ids is set, only the
+// discovered groups it lists are returned.
+func (l *CompliancePlugin) discoverSecurityGroups(ctx context.Context, scan *regionScan) ([]string, error) {
+	input := &resourcegroupstaggingapi.GetResourcesInput{
+		ResourceTypeFilters: []string{"ec2:security-group"},
+	}
+	for _, key := range slices.Sorted(maps.Keys(l.config.TagFilters)) {
+		input.TagFilters = append(input.TagFilters, taggingtypes.TagFilter{
+			Key:    aws.String(key),
+			Values: []string{l.config.TagFilters[key]},
+		})
+	}
+
+	groupIDs := make([]string, 0)
+	for resource, err := range getTaggedResources(ctx, scan.tagging, input) {
+		if err != nil {
+			l.logger.Error("unable to discover security groups through resource groups", "region", scan.region, "error", err)
+			return nil, err
+		}
+		parsed, err := arn.Parse(aws.ToString(resource.ResourceARN))
+		if err != nil {
+			continue
+		}
+		groupID, found := strings.CutPrefix(parsed.Resource, "security-group/")
+		if !found || (len(l.config.GroupIDs) > 0 && !slices.Contains(l.config.GroupIDs, groupID)) {
+			continue
+		}
+		groupIDs = append(groupIDs, groupID)
+	}
+	l.logger.Debug("Discovered security groups through resource groups", "region", scan.region, "groups", len(groupIDs))
+	return groupIDs, nil
+}
+
+func getTaggedResources(ctx context.Context, client TaggingAPI, input *resourcegroupstaggingapi.GetResourcesInput) iter.Seq2[taggingtypes.ResourceTagMapping, error] {
+	return paginate(ctx, func() pager[*resourcegroupstaggingapi.GetResourcesOutput, *resourcegroupstaggingapi.Options] {
+		return resourcegroupstaggingapi.NewGetResourcesPaginator(client, input)
+	}, func(page *resourcegroupstaggingapi.GetResourcesOutput) []taggingtypes.ResourceTagMapping {
+		return page.ResourceTagMappingList
+	})
+}
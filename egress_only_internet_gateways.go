@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"iter"
+	"slices"
+	"strconv"
+)
+
+var egressOnlyInternetGatewayComponent = &proto.Component{
+	Identifier:  "common-components/amazon-egress-only-internet-gateway",
+	Type:        "service",
+	Title:       "Amazon VPC Egress-Only Internet Gateways",
+	Description: "Amazon VPC egress-only internet gateways allow IPv6 traffic from a VPC to the internet while blocking connections initiated from the internet, the IPv6 counterpart of a NAT gateway.",
+	Purpose:     "To give IPv6 resources in private subnets outbound internet access without making them reachable from the internet.",
+}
+
+func (l *CompliancePlugin) evalEgressOnlyInternetGateways(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	found := 0
+	for gateway, err := range getEgressOnlyInternetGateways(ctx, scan.client, &ec2.DescribeEgressOnlyInternetGatewaysInput{}) {
+		if err != nil {
+			l.logger.Error("unable to get egress-only internet gateway", "region", scan.region, "error", err)
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+			continue
+		}
+		// DescribeEgressOnlyInternetGateways only filters on tags, so vpc_ids is applied here. As for
+		// internet gateways, detached gateways are always reported.
+		if len(gateway.Attachments) > 0 && !slices.ContainsFunc(gateway.Attachments, func(attachment types.InternetGatewayAttachment) bool {
+			vpcID := aws.ToString(attachment.VpcId)
+			return (len(l.config.VpcIDs) == 0 || slices.Contains(l.config.VpcIDs, vpcID)) && !scan.excludesVpc(vpcID)
+		}) {
+			continue
+		}
+		found++
+
+		gatewayID := aws.ToString(gateway.EgressOnlyInternetGatewayId)
+
+		labels := internal.MergeMaps(scan.labels, l.tagLabels(gateway.Tags), map[string]string{
+			"type":                            "egress-only-internet-gateway",
+			"egress-only-internet-gateway-id": gatewayID,
+		})
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-egress-only-internet-gateway/%s", gatewayID),
+			Type:       "network",
+			Title:      fmt.Sprintf("Amazon Egress-Only Internet Gateway [%s]", gatewayID),
+			Props: slices.Concat([]*proto.Property{
+				{
+					Name:  "egress-only-internet-gateway-id",
+					Value: gatewayID,
+				},
+				{
+					Name:  "attached",
+					Value: strconv.FormatBool(slices.ContainsFunc(gateway.Attachments, isInternetGatewayAttached)),
+				},
+			}, internetGatewayAttachmentProperties(gateway.Attachments), tagProperties(gateway.Tags)),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, egressOnlyInternetGatewayComponent, inventory, collectionActivities("egress-only internet gateway", "DescribeEgressOnlyInternetGateways"), gateway); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	if found == 0 && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "egress-only-internet-gateway", "egress-only internet gateways", collectionActivities("egress-only internet gateway", "DescribeEgressOnlyInternetGateways"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+func getEgressOnlyInternetGateways(ctx context.Context, client NetworkingAPI, input *ec2.DescribeEgressOnlyInternetGatewaysInput) iter.Seq2[types.EgressOnlyInternetGateway, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeEgressOnlyInternetGatewaysOutput, *ec2.Options] {
+		return ec2.NewDescribeEgressOnlyInternetGatewaysPaginator(client, input)
+	}, func(page *ec2.DescribeEgressOnlyInternetGatewaysOutput) []types.EgressOnlyInternetGateway {
+		return page.EgressOnlyInternetGateways
+	})
+}
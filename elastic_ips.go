@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"slices"
+	"strconv"
+)
+
+var elasticIPComponent = &proto.Component{
+	Identifier:  "common-components/amazon-elastic-ip",
+	Type:        "service",
+	Title:       "Amazon Elastic IP Addresses",
+	Description: "Amazon Elastic IP addresses are static public IPv4 addresses allocated to an account, which can be associated with instances and network interfaces to make them reachable from the internet.",
+	Purpose:     "To maintain an inventory of the public addresses an account exposes, supporting attack surface reviews and the release of unused addresses.",
+}
+
+func (l *CompliancePlugin) evalElasticIPs(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	addresses, err := getElasticIPs(ctx, scan.client, &ec2.DescribeAddressesInput{})
+	if err != nil {
+		l.logger.Error("unable to get Elastic IPs", "region", scan.region, "error", err)
+		return err
+	}
+
+	for _, address := range addresses {
+		// Addresses allocated for EC2-Classic have no allocation ID and are identified by their IP.
+		addressID := aws.ToString(address.AllocationId)
+		if addressID == "" {
+			addressID = aws.ToString(address.PublicIp)
+		}
+		associated := address.AssociationId != nil
+
+		labels := internal.MergeMaps(scan.labels, l.tagLabels(address.Tags), map[string]string{
+			"type":          "elastic-ip",
+			"allocation-id": aws.ToString(address.AllocationId),
+			"public-ip":     aws.ToString(address.PublicIp),
+		})
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-elastic-ip/%s", addressID),
+			Type:       "network",
+			Title:      fmt.Sprintf("Amazon Elastic IP [%s]", aws.ToString(address.PublicIp)),
+			Props: slices.Concat([]*proto.Property{
+				{
+					Name:  "allocation-id",
+					Value: aws.ToString(address.AllocationId),
+				},
+				{
+					Name:  "public-ip",
+					Value: aws.ToString(address.PublicIp),
+				},
+				{
+					Name:  "associated",
+					Value: strconv.FormatBool(associated),
+				},
+				{
+					Name:  "association-id",
+					Value: aws.ToString(address.AssociationId),
+				},
+				{
+					Name:  "instance-id",
+					Value: aws.ToString(address.InstanceId),
+				},
+				{
+					Name:  "network-interface-id",
+					Value: aws.ToString(address.NetworkInterfaceId),
+				},
+				{
+					Name:  "private-ip",
+					Value: aws.ToString(address.PrivateIpAddress),
+				},
+			}, tagProperties(address.Tags)),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, elasticIPComponent, inventory, collectionActivities("Elastic IP", "DescribeAddresses"), address); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	if len(addresses) == 0 && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "elastic-ip", "Elastic IPs", collectionActivities("Elastic IP", "DescribeAddresses"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+// getElasticIPs returns every Elastic IP in the region. DescribeAddresses is not paginated.
+func getElasticIPs(ctx context.Context, client NetworkingAPI, input *ec2.DescribeAddressesInput) ([]types.Address, error) {
+	result, err := client.DescribeAddresses(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return result.Addresses, nil
+}
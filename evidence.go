@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/smithy-go"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/api/sdk"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// newPluginEvidence builds evidence produced by the plugin itself rather than by a policy, such as
+// a record that a region contained no resources. Its UUID is seeded from the labels so the same
+// record keeps the same identity across runs; the seed's extra key is one no label can carry, so the
+// resource type label still tells records of the same region apart. config-hash is left out of the
+// seed, so a configuration change does not change the identity.
+func (l *CompliancePlugin) newPluginEvidence(title string, description string, labels map[string]string, activities []*proto.Activity, props []*proto.Property) (*proto.Evidence, error) {
+	evidenceUUID, err := sdk.SeededUUID(internal.MergeMaps(internal.PrefixKeys(labels, l.config.LabelPrefix), map[string]string{
+		"_evidence-kind": "plugin",
+	}))
+	if err != nil {
+		return nil, err
+	}
+	labels = internal.PrefixKeys(internal.MergeMaps(labels, map[string]string{
+		"config-hash": l.configHash,
+	}), l.config.LabelPrefix)
+
+	now := timestamppb.New(time.Now())
+	return &proto.Evidence{
+		UUID:        evidenceUUID.String(),
+		Title:       title,
+		Description: internal.StringAddressed(description),
+		Labels:      labels,
+		Start:       now,
+		End:         now,
+		Props:       props,
+		Origins:     []*proto.Origin{{Actors: actors()}},
+		Activities:  activities,
+		Status: &proto.EvidenceStatus{
+			Reason:  "pass",
+			Remarks: title,
+			State:   proto.EvidenceStatusState_EVIDENCE_STATUS_STATE_SATISFIED,
+		},
+	}, nil
+}
+
+// reportNoResources records that a collection pass succeeded but found nothing in the region, so an
+// empty result can be told apart from the plugin not having run.
+func (l *CompliancePlugin) reportNoResources(ctx context.Context, scan *regionScan, resourceType string, resourceName string, activities []*proto.Activity, apiHelper runner.ApiHelper) error {
+	l.logger.Debug("No resources found", "region", scan.region, "type", resourceType)
+
+	evidence, err := l.newPluginEvidence(
+		fmt.Sprintf("No %s found in %s", resourceName, scan.region),
+		fmt.Sprintf("The plugin successfully queried %s and found no %s in scope.", scan.region, resourceName),
+		internal.MergeMaps(scan.labels, map[string]string{
+			"type":            resourceType,
+			"resources-found": "0",
+		}),
+		activities,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err = apiHelper.CreateEvidence(ctx, []*proto.Evidence{evidence}); err != nil {
+		l.logger.Error("Failed to send evidences", "region", scan.region, "error", err)
+		return err
+	}
+	return nil
+}
+
+// accessDeniedCodes are the AWS error codes returned when the caller lacks permission for an operation.
+var accessDeniedCodes = []string{"AccessDenied", "AccessDeniedException", "UnauthorizedOperation"}
+
+// isAccessDenied reports whether err, or any error joined into it, is an AWS permissions error.
+func isAccessDenied(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && slices.Contains(accessDeniedCodes, apiErr.ErrorCode())
+}
+
+// reportCollectionSkipped records that a collection pass could not run because the plugin's role lacks
+// the permissions it needs. The evidence is not satisfied, as the resources could not be assessed.
+func (l *CompliancePlugin) reportCollectionSkipped(ctx context.Context, scan *regionScan, resource string, cause error, apiHelper runner.ApiHelper) error {
+	evidence, err := l.newPluginEvidence(
+		fmt.Sprintf("Collection of %s skipped in %s", resource, scan.region),
+		fmt.Sprintf("The plugin lacks the permissions to collect %s in %s: %s", resource, scan.region, cause),
+		internal.MergeMaps(scan.labels, map[string]string{
+			"type":     "collection-skipped",
+			"resource": resource,
+			"reason":   "permissions",
+		}),
+		nil,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+	evidence.Status = &proto.EvidenceStatus{
+		Reason:  "skipped",
+		Remarks: evidence.Title,
+		State:   proto.EvidenceStatusState_EVIDENCE_STATUS_STATE_NOT_SATISFIED,
+	}
+
+	if err = apiHelper.CreateEvidence(ctx, []*proto.Evidence{evidence}); err != nil {
+		l.logger.Error("Failed to send evidences", "region", scan.region, "error", err)
+		return err
+	}
+	return nil
+}
+
+const (
+	scanStatusSuccess = "success"
+	scanStatusPartial = "partial"
+	scanStatusFailed  = "failed"
+)
+
+// reportScanStatus records how the scan of a region went, so a failing region can be identified without
+// parsing the errors returned by the evaluation. passes and failedPasses count the collection passes
+// run in the region, with passes skipped for lack of permissions counted as failed.
+func (l *CompliancePlugin) reportScanStatus(ctx context.Context, region string, labels map[string]string, status string, passes int, failedPasses int, scanErr error, apiHelper runner.ApiHelper) error {
+	props := []*proto.Property{
+		{
+			Name:  "scan-status",
+			Value: status,
+		},
+		{
+			Name:  "passes",
+			Value: strconv.Itoa(passes),
+		},
+		{
+			Name:  "passes-failed",
+			Value: strconv.Itoa(failedPasses),
+		},
+	}
+	if scanErr != nil {
+		props = append(props, &proto.Property{
+			Name:  "error",
+			Value: scanErr.Error(),
+		})
+	}
+
+	evidence, err := l.newPluginEvidence(
+		fmt.Sprintf("Scan of %s: %s", region, status),
+		fmt.Sprintf("Outcome of the plugin's scan of %s.", region),
+		internal.MergeMaps(labels, map[string]string{
+			"type": "region-scan-status",
+		}),
+		nil,
+		props,
+	)
+	if err != nil {
+		return err
+	}
+	if status != scanStatusSuccess {
+		evidence.Status = &proto.EvidenceStatus{
+			Reason:  status,
+			Remarks: evidence.Title,
+			State:   proto.EvidenceStatusState_EVIDENCE_STATUS_STATE_NOT_SATISFIED,
+		}
+	}
+
+	if err = apiHelper.CreateEvidence(ctx, []*proto.Evidence{evidence}); err != nil {
+		l.logger.Error("Failed to send evidences", "region", region, "error", err)
+		return err
+	}
+	return nil
+}
+
+// evaluateResource evaluates a single collected resource against every configured policy and sends
+// the resulting evidence. The component and inventory item are linked to each other and attached as
+// the evidence subjects.
+func (l *CompliancePlugin) evaluateResource(ctx context.Context, request *proto.EvalRequest, apiHelper runner.ApiHelper, scan *regionScan, labels map[string]string, component *proto.Component, item *proto.InventoryItem, activities []*proto.Activity, data interface{}) error {
+	// Past max_resources, resources are left out without error; the run summary reports the truncation.
+	if !scan.summary.recordResource(labels["type"], len(request.GetPolicyPaths())) {
+		return nil
+	}
+
+	item.ImplementedComponents = []*proto.InventoryItemImplementedComponent{
+		{
+			Identifier: component.Identifier,
+		},
+	}
+	subjects := []*proto.Subject{
+		{
+			Type:       proto.SubjectType_SUBJECT_TYPE_COMPONENT,
+			Identifier: component.Identifier,
+		},
+		{
+			Type:       proto.SubjectType_SUBJECT_TYPE_INVENTORY_ITEM,
+			Identifier: item.Identifier,
+		},
+	}
+
+	l.dumpResource(scan, item.Identifier, data)
+
+	evidences, err := l.evaluatePolicies(ctx, request, labels, subjects, []*proto.Component{component}, []*proto.InventoryItem{item}, activities, data)
+
+	if sendErr := apiHelper.CreateEvidence(ctx, evidences); sendErr != nil {
+		l.logger.Error("Failed to send evidences", "region", scan.region, "error", sendErr)
+		err = errors.Join(err, sendErr)
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner/proto"
+	"slices"
+)
+
+// flatSecurityGroup is the normalized representation of a security group added by flat_evidence, for
+// consumers that read evidence without evaluating policies. Its field names are part of the plugin's
+// output and must stay stable, unlike the EC2 types it is built from.
+type flatSecurityGroup struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	VpcID       string     `json:"vpc_id"`
+	OwnerID     string     `json:"owner_id"`
+	Rules       []flatRule `json:"rules"`
+}
+
+// flatRule is a single source or destination of a rule, as expanded by expandRules. Exactly one of
+// Cidr, ReferencedGroupID and PrefixListID is set; Cidr holds IPv4 and IPv6 ranges alike.
+type flatRule struct {
+	Direction         string `json:"direction"`
+	Protocol          string `json:"protocol"`
+	FromPort          int32  `json:"from_port"`
+	ToPort            int32  `json:"to_port"`
+	Cidr              string `json:"cidr,omitempty"`
+	ReferencedGroupID string `json:"referenced_group_id,omitempty"`
+	PrefixListID      string `json:"prefix_list_id,omitempty"`
+	Description       string `json:"description,omitempty"`
+}
+
+func newFlatSecurityGroup(group types.SecurityGroup) *flatSecurityGroup {
+	flat := &flatSecurityGroup{
+		ID:          aws.ToString(group.GroupId),
+		Name:        aws.ToString(group.GroupName),
+		Description: aws.ToString(group.Description),
+		VpcID:       aws.ToString(group.VpcId),
+		OwnerID:     aws.ToString(group.OwnerId),
+		Rules:       make([]flatRule, 0),
+	}
+	rules := slices.Concat(expandRules(ruleDirectionIngress, group.IpPermissions), expandRules(ruleDirectionEgress, group.IpPermissionsEgress))
+	for _, rule := range rules {
+		cidr := rule.CidrIPv4
+		if cidr == "" {
+			cidr = rule.CidrIPv6
+		}
+		flat.Rules = append(flat.Rules, flatRule{
+			Direction:         rule.Direction,
+			Protocol:          rule.Protocol,
+			FromPort:          rule.FromPort,
+			ToPort:            rule.ToPort,
+			Cidr:              cidr,
+			ReferencedGroupID: rule.ReferencedGroupID,
+			PrefixListID:      rule.PrefixListID,
+			Description:       rule.Description,
+		})
+	}
+	return flat
+}
+
+// flatProperties renders the flat representation as a single `flat` property holding its JSON.
+func flatProperties(flat *flatSecurityGroup) ([]*proto.Property, error) {
+	if flat == nil {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(flat)
+	if err != nil {
+		return nil, err
+	}
+	return []*proto.Property{{Name: "flat", Value: string(encoded)}}, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// vpcFlowLogs is the policy input for a VPC's flow log configuration.
+type vpcFlowLogs struct {
+	VpcId           string
+	FlowLogsEnabled bool
+	FlowLogs        []types.FlowLog
+}
+
+var flowLogsComponent = &proto.Component{
+	Identifier:  "common-components/amazon-vpc-flow-logs",
+	Type:        "service",
+	Title:       "Amazon VPC Flow Logs",
+	Description: "Amazon VPC Flow Logs capture information about the IP traffic going to and from network interfaces in a VPC, and publish it to Amazon CloudWatch Logs, Amazon S3, or Amazon Data Firehose.",
+	Purpose:     "To provide an auditable record of network traffic within a VPC, supporting incident investigation, anomaly detection, and network monitoring controls.",
+}
+
+func (l *CompliancePlugin) evalFlowLogs(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	// VPCs that could not be described have already been reported by the region scan.
+	if scan.vpcs == nil {
+		return nil
+	}
+
+	// Flow logs are fetched once and grouped by the VPC they are attached to.
+	flowLogsByVpc := map[string][]types.FlowLog{}
+	for flowLog, err := range getFlowLogs(ctx, scan.client, &ec2.DescribeFlowLogsInput{}) {
+		if err != nil {
+			l.logger.Error("unable to get flow logs", "region", scan.region, "error", err)
+			return err
+		}
+		resourceID := aws.ToString(flowLog.ResourceId)
+		flowLogsByVpc[resourceID] = append(flowLogsByVpc[resourceID], flowLog)
+	}
+
+	for _, vpcID := range slices.Sorted(maps.Keys(scan.vpcs)) {
+		data := vpcFlowLogs{
+			VpcId:    vpcID,
+			FlowLogs: flowLogsByVpc[vpcID],
+		}
+		for _, flowLog := range data.FlowLogs {
+			if aws.ToString(flowLog.FlowLogStatus) == "ACTIVE" {
+				data.FlowLogsEnabled = true
+			}
+		}
+
+		labels := internal.MergeMaps(scan.labels, internetEgressLabels(scan, vpcID), map[string]string{
+			"type":    "vpc-flow-logs",
+			"_vpc-id": vpcID,
+		})
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-vpc/%s", vpcID),
+			Type:       "network",
+			Title:      fmt.Sprintf("Amazon VPC [%s]", vpcID),
+			Props: slices.Concat([]*proto.Property{
+				{
+					Name:  "vpc-id",
+					Value: vpcID,
+				},
+				{
+					Name:  "flow-logs-enabled",
+					Value: strconv.FormatBool(data.FlowLogsEnabled),
+				},
+			}, flowLogProperties(data.FlowLogs)),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, flowLogsComponent, inventory, collectionActivities("VPC flow log", "DescribeFlowLogs"), data); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	if len(scan.vpcs) == 0 && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "vpc-flow-logs", "VPCs", collectionActivities("VPC flow log", "DescribeFlowLogs"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+// flowLogProperties renders each flow log as `flow-log/<n>/<field>` properties.
+func flowLogProperties(flowLogs []types.FlowLog) []*proto.Property {
+	props := make([]*proto.Property, 0)
+	for i, flowLog := range flowLogs {
+		prefix := fmt.Sprintf("flow-log/%d", i)
+		destination := aws.ToString(flowLog.LogDestination)
+		if destination == "" {
+			destination = aws.ToString(flowLog.LogGroupName)
+		}
+		props = append(props,
+			&proto.Property{Name: prefix + "/id", Value: aws.ToString(flowLog.FlowLogId)},
+			&proto.Property{Name: prefix + "/status", Value: aws.ToString(flowLog.FlowLogStatus)},
+			&proto.Property{Name: prefix + "/destination-type", Value: string(flowLog.LogDestinationType)},
+			&proto.Property{Name: prefix + "/destination", Value: destination},
+			&proto.Property{Name: prefix + "/traffic-type", Value: string(flowLog.TrafficType)},
+		)
+	}
+	return props
+}
+
+func getFlowLogs(ctx context.Context, client NetworkingAPI, input *ec2.DescribeFlowLogsInput) iter.Seq2[types.FlowLog, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeFlowLogsOutput, *ec2.Options] {
+		return ec2.NewDescribeFlowLogsPaginator(client, input)
+	}, func(page *ec2.DescribeFlowLogsOutput) []types.FlowLog {
+		return page.FlowLogs
+	})
+}
@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"context"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+)
+
+// SynchronizedApiHelper is a runner.ApiHelper that can be shared by concurrent workers. Evidence is
+// queued on an EvidenceCollector and sent in batches of batchSize, which keeps the number of calls to
+// the agent low in large accounts.
+type SynchronizedApiHelper struct {
+	collector *EvidenceCollector
+	helper    runner.ApiHelper
+	batchSize int
+}
+
+// NewSynchronizedApiHelper returns a helper queuing evidence on collector and sending it through helper
+// in batches of batchSize. A batchSize below one sends evidence as soon as it is created.
+func NewSynchronizedApiHelper(collector *EvidenceCollector, helper runner.ApiHelper, batchSize int) *SynchronizedApiHelper {
+	return &SynchronizedApiHelper{
+		collector: collector,
+		helper:    helper,
+		batchSize: max(batchSize, 1),
+	}
+}
+
+// CreateEvidence queues evidence, sending every full batch. An error reports a batch that could not
+// be sent; evidence in later batches is unaffected. Once ctx is done evidence is only queued, to be
+// sent by Flush.
+func (s *SynchronizedApiHelper) CreateEvidence(ctx context.Context, evidence []*proto.Evidence) error {
+	s.collector.Add(evidence)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return s.collector.flushFull(ctx, s.helper, s.batchSize)
+}
+
+// Flush sends all queued evidence. It must be called once every worker has finished.
+func (s *SynchronizedApiHelper) Flush(ctx context.Context) error {
+	return s.collector.Flush(ctx, s.helper, s.batchSize)
+}
@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"github.com/compliance-framework/agent/runner/proto"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// records returns count evidence records with UUIDs numbered from first.
+func records(first int, count int) []*proto.Evidence {
+	evidence := make([]*proto.Evidence, 0, count)
+	for i := range count {
+		evidence = append(evidence, &proto.Evidence{UUID: strconv.Itoa(first + i)})
+	}
+	return evidence
+}
+
+func batchSizes(batches [][]*proto.Evidence) []int {
+	sizes := make([]int, 0, len(batches))
+	for _, batch := range batches {
+		sizes = append(sizes, len(batch))
+	}
+	return sizes
+}
+
+func TestSynchronizedApiHelperBatches(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchSize int
+		// adds are the sizes of the successive CreateEvidence calls.
+		adds []int
+		// beforeFlush and afterFlush are the sizes of the batches sent before and by Flush.
+		beforeFlush []int
+		afterFlush  []int
+	}{
+		{name: "below one batch", batchSize: 3, adds: []int{1, 1}, beforeFlush: []int{}, afterFlush: []int{2}},
+		{name: "exactly one batch", batchSize: 3, adds: []int{2, 1}, beforeFlush: []int{3}, afterFlush: []int{3}},
+		{name: "batch boundary crossed", batchSize: 3, adds: []int{2, 2}, beforeFlush: []int{3}, afterFlush: []int{3, 1}},
+		{name: "several batches at once", batchSize: 2, adds: []int{5}, beforeFlush: []int{2, 2}, afterFlush: []int{2, 2, 1}},
+		{name: "batch size below one", batchSize: 0, adds: []int{2, 1}, beforeFlush: []int{1, 1, 1}, afterFlush: []int{1, 1, 1}},
+		{name: "nothing queued", batchSize: 2, beforeFlush: []int{}, afterFlush: []int{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			apiHelper := &recordingApiHelper{}
+			collector := &EvidenceCollector{}
+			helper := NewSynchronizedApiHelper(collector, apiHelper, test.batchSize)
+
+			next := 0
+			for _, count := range test.adds {
+				if err := helper.CreateEvidence(ctx, records(next, count)); err != nil {
+					t.Fatalf("CreateEvidence: %v", err)
+				}
+				next += count
+			}
+			if got := batchSizes(apiHelper.batches); !slices.Equal(got, test.beforeFlush) {
+				t.Errorf("batches before Flush = %v, want %v", got, test.beforeFlush)
+			}
+			if err := helper.Flush(ctx); err != nil {
+				t.Fatalf("Flush: %v", err)
+			}
+			if got := batchSizes(apiHelper.batches); !slices.Equal(got, test.afterFlush) {
+				t.Errorf("batches after Flush = %v, want %v", got, test.afterFlush)
+			}
+			if collector.Sent() != next {
+				t.Errorf("Sent() = %d, want %d", collector.Sent(), next)
+			}
+		})
+	}
+}
+
+func TestSynchronizedApiHelperKeepsEvidenceAfterFailedBatch(t *testing.T) {
+	ctx := context.Background()
+	errSend := errors.New("agent unavailable")
+	apiHelper := &recordingApiHelper{err: errSend}
+	collector := &EvidenceCollector{}
+	helper := NewSynchronizedApiHelper(collector, apiHelper, 2)
+
+	if err := helper.CreateEvidence(ctx, records(0, 3)); !errors.Is(err, errSend) {
+		t.Fatalf("CreateEvidence error = %v, want %v", err, errSend)
+	}
+	// The failed batch is dropped, the remaining record is still sent once the agent recovers.
+	apiHelper.err = nil
+	if err := helper.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if got := apiHelper.sent(); got != "0,1,2" {
+		t.Errorf("attempted %s, want 0,1,2", got)
+	}
+	if collector.Sent() != 1 {
+		t.Errorf("Sent() = %d, want 1", collector.Sent())
+	}
+}
+
+func TestSynchronizedApiHelperQueuesOnceCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	apiHelper := &recordingApiHelper{}
+	collector := &EvidenceCollector{}
+	helper := NewSynchronizedApiHelper(collector, apiHelper, 1)
+
+	if err := helper.CreateEvidence(ctx, records(0, 2)); err != nil {
+		t.Fatalf("CreateEvidence: %v", err)
+	}
+	if len(apiHelper.batches) != 0 {
+		t.Errorf("%d batches sent after cancellation, want 0", len(apiHelper.batches))
+	}
+	if err := helper.Flush(context.WithoutCancel(ctx)); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if got := apiHelper.sent(); got != "0,1" {
+		t.Errorf("sent %s, want 0,1", got)
+	}
+}
@@ -0,0 +1,379 @@
+// Package awsmock provides in-memory fakes of the AWS APIs used by the plugin, serving canned,
+// paginated responses so the evaluation path can be exercised without AWS.
+package awsmock
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/ram"
+	ramtypes "github.com/aws/aws-sdk-go-v2/service/ram/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	taggingtypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"strconv"
+)
+
+// EC2 is a fake EC2 client. Each field holds the pages returned for an operation, in order; the
+// NextToken handed back to callers is the index of the following page. Errors maps an operation
+// name, e.g. "DescribeSecurityGroups", to an error returned in place of every response, or an
+// operation name and page index, e.g. "DescribeSecurityGroups/1", to an error returned in place of
+// that page only.
+type EC2 struct {
+	SecurityGroups            [][]types.SecurityGroup
+	NetworkAcls               [][]types.NetworkAcl
+	Vpcs                      [][]types.Vpc
+	FlowLogs                  [][]types.FlowLog
+	Subnets                   [][]types.Subnet
+	PrefixLists               [][]types.ManagedPrefixList
+	NetworkInterfaces         [][]types.NetworkInterface
+	RouteTables               [][]types.RouteTable
+	NatGateways               [][]types.NatGateway
+	InternetGateways          [][]types.InternetGateway
+	VpcEndpoints              [][]types.VpcEndpoint
+	TransitGateways           [][]types.TransitGateway
+	TransitGatewayAttachments [][]types.TransitGatewayAttachment
+	VpcPeeringConnections     [][]types.VpcPeeringConnection
+	DhcpOptions               [][]types.DhcpOptions
+	// Addresses, VpnConnections and CustomerGateways are returned in full, as their operations are not
+	// paginated.
+	Addresses                        []types.Address
+	VpnConnections                   []types.VpnConnection
+	CustomerGateways                 []types.CustomerGateway
+	SecurityGroupRules               [][]types.SecurityGroupRule
+	ClientVpnEndpoints               [][]types.ClientVpnEndpoint
+	NetworkInsightsPaths             [][]types.NetworkInsightsPath
+	NetworkInsightsAnalyses          [][]types.NetworkInsightsAnalysis
+	EgressOnlyInternetGateways       [][]types.EgressOnlyInternetGateway
+	TrafficMirrorTargets             [][]types.TrafficMirrorTarget
+	TrafficMirrorSessions            [][]types.TrafficMirrorSession
+	VpcEndpointServiceConfigurations [][]types.ServiceConfiguration
+	// PrefixListEntries holds the pages of entries per prefix list ID, and
+	// VpcEndpointServicePermissions the pages of allowed principals per endpoint service ID.
+	PrefixListEntries             map[string][][]types.PrefixListEntry
+	VpcEndpointServicePermissions map[string][][]types.AllowedPrincipal
+
+	Errors map[string]error
+	// Hook, when set, is called at the start of every call with the call's context and operation name,
+	// and an error it returns is returned in place of a response. It may block, e.g. to observe
+	// concurrent calls or to wait for a cancellation.
+	Hook func(ctx context.Context, operation string) error
+}
+
+func (m *EC2) DescribeSecurityGroups(ctx context.Context, input *ec2.DescribeSecurityGroupsInput, _ ...func(*ec2.Options)) (*ec2.DescribeSecurityGroupsOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeSecurityGroups", m.SecurityGroups, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeSecurityGroupsOutput{SecurityGroups: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeNetworkAcls(ctx context.Context, input *ec2.DescribeNetworkAclsInput, _ ...func(*ec2.Options)) (*ec2.DescribeNetworkAclsOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeNetworkAcls", m.NetworkAcls, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeNetworkAclsOutput{NetworkAcls: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeVpcs(ctx context.Context, input *ec2.DescribeVpcsInput, _ ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeVpcs", m.Vpcs, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeVpcsOutput{Vpcs: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeFlowLogs(ctx context.Context, input *ec2.DescribeFlowLogsInput, _ ...func(*ec2.Options)) (*ec2.DescribeFlowLogsOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeFlowLogs", m.FlowLogs, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeFlowLogsOutput{FlowLogs: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeSubnets(ctx context.Context, input *ec2.DescribeSubnetsInput, _ ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeSubnets", m.Subnets, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeSubnetsOutput{Subnets: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeManagedPrefixLists(ctx context.Context, input *ec2.DescribeManagedPrefixListsInput, _ ...func(*ec2.Options)) (*ec2.DescribeManagedPrefixListsOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeManagedPrefixLists", m.PrefixLists, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeManagedPrefixListsOutput{PrefixLists: items, NextToken: next}, nil
+}
+
+func (m *EC2) GetManagedPrefixListEntries(ctx context.Context, input *ec2.GetManagedPrefixListEntriesInput, _ ...func(*ec2.Options)) (*ec2.GetManagedPrefixListEntriesOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "GetManagedPrefixListEntries", m.PrefixListEntries[aws.ToString(input.PrefixListId)], input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.GetManagedPrefixListEntriesOutput{Entries: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeNetworkInterfaces(ctx context.Context, input *ec2.DescribeNetworkInterfacesInput, _ ...func(*ec2.Options)) (*ec2.DescribeNetworkInterfacesOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeNetworkInterfaces", m.NetworkInterfaces, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeNetworkInterfacesOutput{NetworkInterfaces: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeRouteTables(ctx context.Context, input *ec2.DescribeRouteTablesInput, _ ...func(*ec2.Options)) (*ec2.DescribeRouteTablesOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeRouteTables", m.RouteTables, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeRouteTablesOutput{RouteTables: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeNatGateways(ctx context.Context, input *ec2.DescribeNatGatewaysInput, _ ...func(*ec2.Options)) (*ec2.DescribeNatGatewaysOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeNatGateways", m.NatGateways, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeNatGatewaysOutput{NatGateways: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeInternetGateways(ctx context.Context, input *ec2.DescribeInternetGatewaysInput, _ ...func(*ec2.Options)) (*ec2.DescribeInternetGatewaysOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeInternetGateways", m.InternetGateways, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeInternetGatewaysOutput{InternetGateways: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeVpcEndpoints(ctx context.Context, input *ec2.DescribeVpcEndpointsInput, _ ...func(*ec2.Options)) (*ec2.DescribeVpcEndpointsOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeVpcEndpoints", m.VpcEndpoints, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeVpcEndpointsOutput{VpcEndpoints: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeTransitGateways(ctx context.Context, input *ec2.DescribeTransitGatewaysInput, _ ...func(*ec2.Options)) (*ec2.DescribeTransitGatewaysOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeTransitGateways", m.TransitGateways, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeTransitGatewaysOutput{TransitGateways: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeTransitGatewayAttachments(ctx context.Context, input *ec2.DescribeTransitGatewayAttachmentsInput, _ ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayAttachmentsOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeTransitGatewayAttachments", m.TransitGatewayAttachments, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeTransitGatewayAttachmentsOutput{TransitGatewayAttachments: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeVpcPeeringConnections(ctx context.Context, input *ec2.DescribeVpcPeeringConnectionsInput, _ ...func(*ec2.Options)) (*ec2.DescribeVpcPeeringConnectionsOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeVpcPeeringConnections", m.VpcPeeringConnections, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeVpcPeeringConnectionsOutput{VpcPeeringConnections: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeDhcpOptions(ctx context.Context, input *ec2.DescribeDhcpOptionsInput, _ ...func(*ec2.Options)) (*ec2.DescribeDhcpOptionsOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeDhcpOptions", m.DhcpOptions, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeDhcpOptionsOutput{DhcpOptions: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeAddresses(ctx context.Context, _ *ec2.DescribeAddressesInput, _ ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error) {
+	items, _, err := page(ctx, m.Hook, m.Errors, "DescribeAddresses", [][]types.Address{m.Addresses}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeAddressesOutput{Addresses: items}, nil
+}
+
+func (m *EC2) DescribeVpnConnections(ctx context.Context, _ *ec2.DescribeVpnConnectionsInput, _ ...func(*ec2.Options)) (*ec2.DescribeVpnConnectionsOutput, error) {
+	items, _, err := page(ctx, m.Hook, m.Errors, "DescribeVpnConnections", [][]types.VpnConnection{m.VpnConnections}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeVpnConnectionsOutput{VpnConnections: items}, nil
+}
+
+func (m *EC2) DescribeCustomerGateways(ctx context.Context, _ *ec2.DescribeCustomerGatewaysInput, _ ...func(*ec2.Options)) (*ec2.DescribeCustomerGatewaysOutput, error) {
+	items, _, err := page(ctx, m.Hook, m.Errors, "DescribeCustomerGateways", [][]types.CustomerGateway{m.CustomerGateways}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeCustomerGatewaysOutput{CustomerGateways: items}, nil
+}
+
+func (m *EC2) DescribeSecurityGroupRules(ctx context.Context, input *ec2.DescribeSecurityGroupRulesInput, _ ...func(*ec2.Options)) (*ec2.DescribeSecurityGroupRulesOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeSecurityGroupRules", m.SecurityGroupRules, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeSecurityGroupRulesOutput{SecurityGroupRules: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeClientVpnEndpoints(ctx context.Context, input *ec2.DescribeClientVpnEndpointsInput, _ ...func(*ec2.Options)) (*ec2.DescribeClientVpnEndpointsOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeClientVpnEndpoints", m.ClientVpnEndpoints, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeClientVpnEndpointsOutput{ClientVpnEndpoints: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeNetworkInsightsPaths(ctx context.Context, input *ec2.DescribeNetworkInsightsPathsInput, _ ...func(*ec2.Options)) (*ec2.DescribeNetworkInsightsPathsOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeNetworkInsightsPaths", m.NetworkInsightsPaths, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeNetworkInsightsPathsOutput{NetworkInsightsPaths: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeNetworkInsightsAnalyses(ctx context.Context, input *ec2.DescribeNetworkInsightsAnalysesInput, _ ...func(*ec2.Options)) (*ec2.DescribeNetworkInsightsAnalysesOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeNetworkInsightsAnalyses", m.NetworkInsightsAnalyses, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeNetworkInsightsAnalysesOutput{NetworkInsightsAnalyses: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeEgressOnlyInternetGateways(ctx context.Context, input *ec2.DescribeEgressOnlyInternetGatewaysInput, _ ...func(*ec2.Options)) (*ec2.DescribeEgressOnlyInternetGatewaysOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeEgressOnlyInternetGateways", m.EgressOnlyInternetGateways, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeEgressOnlyInternetGatewaysOutput{EgressOnlyInternetGateways: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeTrafficMirrorTargets(ctx context.Context, input *ec2.DescribeTrafficMirrorTargetsInput, _ ...func(*ec2.Options)) (*ec2.DescribeTrafficMirrorTargetsOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeTrafficMirrorTargets", m.TrafficMirrorTargets, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeTrafficMirrorTargetsOutput{TrafficMirrorTargets: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeTrafficMirrorSessions(ctx context.Context, input *ec2.DescribeTrafficMirrorSessionsInput, _ ...func(*ec2.Options)) (*ec2.DescribeTrafficMirrorSessionsOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeTrafficMirrorSessions", m.TrafficMirrorSessions, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeTrafficMirrorSessionsOutput{TrafficMirrorSessions: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeVpcEndpointServiceConfigurations(ctx context.Context, input *ec2.DescribeVpcEndpointServiceConfigurationsInput, _ ...func(*ec2.Options)) (*ec2.DescribeVpcEndpointServiceConfigurationsOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeVpcEndpointServiceConfigurations", m.VpcEndpointServiceConfigurations, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeVpcEndpointServiceConfigurationsOutput{ServiceConfigurations: items, NextToken: next}, nil
+}
+
+func (m *EC2) DescribeVpcEndpointServicePermissions(ctx context.Context, input *ec2.DescribeVpcEndpointServicePermissionsInput, _ ...func(*ec2.Options)) (*ec2.DescribeVpcEndpointServicePermissionsOutput, error) {
+	items, next, err := page(ctx, m.Hook, m.Errors, "DescribeVpcEndpointServicePermissions", m.VpcEndpointServicePermissions[aws.ToString(input.ServiceId)], input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ec2.DescribeVpcEndpointServicePermissionsOutput{AllowedPrincipals: items, NextToken: next}, nil
+}
+
+// page returns the page addressed by token, along with the token of the following page, if any. The
+// error of hook, when set, or an error set in errs for the operation, or for the operation and the
+// page's index, is returned instead.
+func page[T any](ctx context.Context, hook func(context.Context, string) error, errs map[string]error, operation string, pages [][]T, token *string) ([]T, *string, error) {
+	if hook != nil {
+		if err := hook(ctx, operation); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := errs[operation]; err != nil {
+		return nil, nil, err
+	}
+
+	index := 0
+	if token != nil {
+		var err error
+		if index, err = strconv.Atoi(*token); err != nil || index < 0 || index >= len(pages) {
+			return nil, nil, fmt.Errorf("%s: invalid NextToken %q", operation, *token)
+		}
+	}
+	if err := errs[operation+"/"+strconv.Itoa(index)]; err != nil {
+		return nil, nil, err
+	}
+	if len(pages) == 0 {
+		return nil, nil, nil
+	}
+
+	var next *string
+	if index+1 < len(pages) {
+		next = aws.String(strconv.Itoa(index + 1))
+	}
+	return pages[index], next, nil
+}
+
+// STS is a fake STS client returning a fixed caller identity, or Err when set.
+type STS struct {
+	Account string
+	Arn     string
+	Err     error
+}
+
+func (m *STS) GetCallerIdentity(context.Context, *sts.GetCallerIdentityInput, ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return &sts.GetCallerIdentityOutput{
+		Account: aws.String(m.Account),
+		Arn:     aws.String(m.Arn),
+	}, nil
+}
+
+// Tagging is a fake Resource Groups Tagging API client. Resources holds the pages returned by
+// GetResources, whose PaginationToken is the index of the following page, and Err an error returned
+// in place of a response.
+type Tagging struct {
+	Resources [][]taggingtypes.ResourceTagMapping
+	Err       error
+}
+
+func (m *Tagging) GetResources(ctx context.Context, input *resourcegroupstaggingapi.GetResourcesInput, _ ...func(*resourcegroupstaggingapi.Options)) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+	items, next, err := page(ctx, nil, map[string]error{"GetResources": m.Err}, "GetResources", m.Resources, input.PaginationToken)
+	if err != nil {
+		return nil, err
+	}
+	return &resourcegroupstaggingapi.GetResourcesOutput{ResourceTagMappingList: items, PaginationToken: next}, nil
+}
+
+// RAM is a fake Resource Access Manager client. Resources and Associations hold the pages returned by
+// ListResources and GetResourceShareAssociations, and Errors maps an operation name, or an operation
+// name and page index, to an error as for EC2.
+type RAM struct {
+	Resources    [][]ramtypes.Resource
+	Associations [][]ramtypes.ResourceShareAssociation
+	Errors       map[string]error
+}
+
+func (m *RAM) ListResources(ctx context.Context, input *ram.ListResourcesInput, _ ...func(*ram.Options)) (*ram.ListResourcesOutput, error) {
+	items, next, err := page(ctx, nil, m.Errors, "ListResources", m.Resources, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ram.ListResourcesOutput{Resources: items, NextToken: next}, nil
+}
+
+func (m *RAM) GetResourceShareAssociations(ctx context.Context, input *ram.GetResourceShareAssociationsInput, _ ...func(*ram.Options)) (*ram.GetResourceShareAssociationsOutput, error) {
+	items, next, err := page(ctx, nil, m.Errors, "GetResourceShareAssociations", m.Associations, input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &ram.GetResourceShareAssociationsOutput{ResourceShareAssociations: items, NextToken: next}, nil
+}
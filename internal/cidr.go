@@ -0,0 +1,35 @@
+package internal
+
+import "net/netip"
+
+// nonPublicPrefixes are the IPv4 and IPv6 ranges that are not routable on the internet: private,
+// shared (carrier-grade NAT), loopback, link-local and unique local addresses.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+}
+
+// PrefixContains reports whether every address of inner lies within outer. Prefixes of different
+// address families never contain each other.
+func PrefixContains(outer, inner netip.Prefix) bool {
+	return outer.Addr().Is4() == inner.Addr().Is4() && outer.Bits() <= inner.Bits() && outer.Contains(inner.Addr())
+}
+
+// IsPublicPrefix reports whether prefix includes addresses routable on the internet, i.e. it is not
+// wholly within a private, loopback or link-local range.
+func IsPublicPrefix(prefix netip.Prefix) bool {
+	prefix = prefix.Masked()
+	for _, nonPublic := range nonPublicPrefixes {
+		if PrefixContains(nonPublic, prefix) {
+			return false
+		}
+	}
+	return true
+}
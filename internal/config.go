@@ -0,0 +1,527 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/hashicorp/go-hclog"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults applied by ParseConfig to keys that are not configured.
+const (
+	// DefaultMaxRetries is the number of times a throttled or otherwise retryable AWS call is retried.
+	DefaultMaxRetries = 5
+	// DefaultMaxConcurrency is the number of regions scanned in parallel.
+	DefaultMaxConcurrency = 4
+	// DefaultEvidenceBatchSize is the number of evidence records sent per call to the agent.
+	DefaultEvidenceBatchSize = 100
+	// DefaultEvalTimeout bounds a whole evaluation.
+	DefaultEvalTimeout = 300 * time.Second
+	// DefaultAWSRequestTimeout bounds a single AWS API request.
+	DefaultAWSRequestTimeout = 30 * time.Second
+)
+
+// ConfigKind is the form of a configuration value. Every value is configured as a string.
+type ConfigKind string
+
+const (
+	ConfigString ConfigKind = "string"
+	// ConfigList values are comma-separated.
+	ConfigList ConfigKind = "list"
+	// ConfigPairs values are comma-separated key=value pairs.
+	ConfigPairs           ConfigKind = "pairs"
+	ConfigBoolean         ConfigKind = "boolean"
+	ConfigInteger         ConfigKind = "non-negative-integer"
+	ConfigPositiveInteger ConfigKind = "positive-integer"
+	ConfigPositiveNumber  ConfigKind = "positive-number"
+	// ConfigSeconds values are a whole number of seconds.
+	ConfigSeconds ConfigKind = "seconds"
+)
+
+// ConfigKey describes a configuration key understood by ParseConfig. ParseConfig enforces the
+// constraints described here, which are also published in the plugin's configuration schema.
+type ConfigKey struct {
+	Name string
+	Kind ConfigKind
+	// Description is a one-line summary of the key.
+	Description string
+	// Values, when set, are the only values accepted.
+	Values []string
+	// Pattern, when set, is a regular expression the value must match. Values are not trimmed first.
+	Pattern string
+	// Minimum and Maximum bound the values of integer and seconds keys. A Maximum of zero is unbounded.
+	Minimum int
+	Maximum int
+	// Operational keys tune how a run is carried out without changing what it reports, and are left
+	// out of the configuration fingerprint.
+	Operational bool
+}
+
+// ConfigKeys lists every configuration key understood by ParseConfig. It is the source of the
+// plugin's capabilities and configuration schema, so a key parsed by ParseConfig must be listed here.
+var ConfigKeys = []ConfigKey{
+	{Name: "regions", Kind: ConfigList, Description: "AWS regions to scan, all in the same partition. Defaults to the SDK's region."},
+	{Name: "profile", Kind: ConfigString, Description: "Named profile of the shared AWS configuration to load credentials from."},
+	{Name: "aws_access_key_id", Kind: ConfigString, Description: "Access key ID of static credentials, set with aws_secret_access_key."},
+	{Name: "aws_secret_access_key", Kind: ConfigString, Description: "Secret access key of static credentials, set with aws_access_key_id."},
+	{Name: "aws_session_token", Kind: ConfigString, Description: "Session token of temporary static credentials."},
+	{Name: "assume_role_arn", Kind: ConfigString, Description: "ARN of an IAM role to assume before scanning."},
+	{Name: "assume_role_chain", Kind: ConfigList, Description: "IAM role ARNs assumed in order, each with the previous role's credentials."},
+	{Name: "external_id", Kind: ConfigString, Description: "External ID passed when assuming the last role."},
+	{Name: "assume_role_session_name", Kind: ConfigString, Description: "Session name of assumed roles, recorded in CloudTrail.", Pattern: `^[\w+=,.@-]{2,64}$`},
+	{Name: "assume_role_duration_seconds", Kind: ConfigSeconds, Description: "Duration of assumed role sessions. Defaults to the SDK's 15 minutes.", Minimum: 900, Maximum: 43200},
+	{Name: "endpoint_url", Kind: ConfigString, Description: "Absolute URL of a custom endpoint for AWS API calls, e.g. LocalStack."},
+	{Name: "disable_ssl", Kind: ConfigBoolean, Description: "Skip TLS certificate verification of the endpoint_url endpoint."},
+	{Name: "vpc_ids", Kind: ConfigList, Description: "VPC IDs to scope collection to."},
+	{Name: "group_ids", Kind: ConfigList, Description: "Security group IDs to evaluate."},
+	{Name: "skip_default_vpc", Kind: ConfigBoolean, Description: "Leave out resources in default VPCs."},
+	{Name: "tag_filters", Kind: ConfigPairs, Description: "Tags a security group must carry to be scanned."},
+	{Name: "discovery", Kind: ConfigString, Description: "How security groups are found.", Values: []string{DiscoveryRegionScan, DiscoveryResourceGroups}},
+	{Name: "label_tags", Kind: ConfigList, Description: "Tag keys promoted to evidence labels."},
+	{Name: "label_prefix", Kind: ConfigString, Description: "Namespace for every emitted label key."},
+	{Name: "sensitive_ports", Kind: ConfigList, Description: "Ports reported when open to the internet. Empty to disable the check."},
+	{Name: "approved_cidrs", Kind: ConfigList, Description: "Public CIDRs ingress is approved to be open to."},
+	{Name: "severity_levels", Kind: ConfigPairs, Description: "signal=severity overrides of the security group severity heuristic."},
+	{Name: "use_security_group_rules_api", Kind: ConfigBoolean, Description: "Also read rules through DescribeSecurityGroupRules, for rule IDs and tags."},
+	{Name: "require_ingress_rules", Kind: ConfigBoolean, Description: "Skip security groups without ingress rules."},
+	{Name: "skip_default_group", Kind: ConfigBoolean, Description: "Skip the default security group of every VPC."},
+	{Name: "report_private_ips", Kind: ConfigBoolean, Description: "List the private IPs using each security group."},
+	{Name: "flat_evidence", Kind: ConfigBoolean, Description: "Add a normalized, flat representation of each security group."},
+	{Name: "include_states", Kind: ConfigList, Description: "States, or resource:state entries, resources are evaluated in."},
+	{Name: "max_retries", Kind: ConfigInteger, Description: "Retries of a throttled or failed AWS call.", Operational: true},
+	{Name: "max_concurrency", Kind: ConfigPositiveInteger, Description: "Collection passes run in parallel across all regions.", Minimum: 1, Operational: true},
+	{Name: "eval_timeout", Kind: ConfigSeconds, Description: "Time an evaluation may run before it is aborted.", Minimum: 1, Operational: true},
+	{Name: "aws_request_timeout", Kind: ConfigSeconds, Description: "Time a single AWS API request may take.", Minimum: 1, Operational: true},
+	{Name: "api_rate_limit", Kind: ConfigPositiveNumber, Description: "Maximum AWS API requests per second. Unlimited by default.", Pattern: `^(0*[1-9][0-9]*(\.[0-9]*)?|0*\.[0-9]*[1-9][0-9]*)$`, Operational: true},
+	{Name: "evidence_batch_size", Kind: ConfigPositiveInteger, Description: "Evidence records sent to the agent per call.", Minimum: 1, Operational: true},
+	{Name: "max_resources", Kind: ConfigInteger, Description: "Maximum resources evaluated by a run, or 0 for no cap."},
+	{Name: "dry_run", Kind: ConfigBoolean, Description: "Log evidence instead of sending it.", Operational: true},
+	{Name: "debug_dump_dir", Kind: ConfigString, Description: "Directory to write every resource passed to policies to.", Operational: true},
+	{Name: "aws_sdk_debug", Kind: ConfigBoolean, Description: "Log every AWS request, response and retry.", Operational: true},
+	{Name: "resources", Kind: ConfigList, Description: "Resource types to collect. Defaults to every type."},
+	{Name: "log_level", Kind: ConfigString, Description: "Log level of the plugin.", Values: []string{"trace", "debug", "info", "warn", "error"}, Operational: true},
+}
+
+// configKey returns the ConfigKeys entry named name. It panics for a key missing from ConfigKeys, so a
+// key parsed without being listed fails as soon as it is parsed.
+func configKey(name string) ConfigKey {
+	index := slices.IndexFunc(ConfigKeys, func(key ConfigKey) bool {
+		return key.Name == name
+	})
+	if index < 0 {
+		panic("configuration key " + name + " is not listed in ConfigKeys")
+	}
+	return ConfigKeys[index]
+}
+
+// Constraint describes the values accepted for the key beyond its kind, e.g. "an integer from 1 to 10"
+// or "one of a, b or c". It is empty for keys constrained by their kind alone, or by a Pattern.
+func (k ConfigKey) Constraint() string {
+	if len(k.Values) > 0 {
+		if len(k.Values) == 1 {
+			return k.Values[0]
+		}
+		return "one of " + strings.Join(k.Values[:len(k.Values)-1], ", ") + " or " + k.Values[len(k.Values)-1]
+	}
+	if k.Kind != ConfigInteger && k.Kind != ConfigPositiveInteger && k.Kind != ConfigSeconds {
+		return ""
+	}
+	unit, qualified := "an integer", "integer"
+	if k.Kind == ConfigSeconds {
+		unit, qualified = "a number of seconds", "number of seconds"
+	}
+	switch {
+	case k.Maximum > 0:
+		return fmt.Sprintf("%s from %d to %d", unit, k.Minimum, k.Maximum)
+	case k.Minimum == 0:
+		return "a non-negative " + qualified
+	case k.Minimum == 1:
+		return "a positive " + qualified
+	default:
+		return fmt.Sprintf("%s of at least %d", unit, k.Minimum)
+	}
+}
+
+// parseInteger parses the integer or seconds key name from raw, within the bounds ConfigKeys gives it.
+// ok is false when the key is not set. Only digits are accepted, as in the configuration schema, so
+// signs and surrounding whitespace are rejected.
+func parseInteger(raw map[string]string, name string) (n int, ok bool, err error) {
+	key := configKey(name)
+	value := raw[name]
+	if value == "" {
+		return 0, false, nil
+	}
+	n, err = strconv.Atoi(value)
+	if err != nil || !digitsPattern.MatchString(value) || n < key.Minimum || (key.Maximum > 0 && n > key.Maximum) {
+		return 0, false, fmt.Errorf("invalid configuration: %s %q must be %s", name, value, key.Constraint())
+	}
+	return n, true, nil
+}
+
+// parseChoice returns the value of the key name from raw, which must be exactly one of the key's
+// Values, or fallback when the key is not set.
+func parseChoice(raw map[string]string, name string, fallback string) (string, error) {
+	key := configKey(name)
+	value := raw[name]
+	if value == "" {
+		return fallback, nil
+	}
+	if !slices.Contains(key.Values, value) {
+		return "", fmt.Errorf("invalid configuration: %s %q must be %s", name, value, key.Constraint())
+	}
+	return value, nil
+}
+
+// OmitOperationalConfig returns a copy of config without its operational keys, see
+// ConfigKey.Operational.
+func OmitOperationalConfig(config map[string]string) map[string]string {
+	result := make(map[string]string, len(config))
+	for key, value := range config {
+		index := slices.IndexFunc(ConfigKeys, func(k ConfigKey) bool {
+			return k.Name == key
+		})
+		if index < 0 || !ConfigKeys[index].Operational {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// IsConfigKey reports whether name is listed in ConfigKeys.
+func IsConfigKey(name string) bool {
+	return slices.ContainsFunc(ConfigKeys, func(key ConfigKey) bool {
+		return key.Name == name
+	})
+}
+
+// Values of the discovery config key.
+const (
+	// DiscoveryRegionScan describes every security group of a region, narrowed by the EC2 filters.
+	DiscoveryRegionScan = "region-scan"
+	// DiscoveryResourceGroups finds the security groups matching tag_filters through the Resource
+	// Groups Tagging API, and only describes those.
+	DiscoveryResourceGroups = "resource-groups"
+)
+
+// DefaultAssumeRoleSessionName names the sessions of assumed roles, so the plugin's calls can be told
+// apart in CloudTrail.
+const DefaultAssumeRoleSessionName = "compliance-framework"
+
+// sessionNamePattern matches the role session names accepted by STS.
+var sessionNamePattern = regexp.MustCompile(configKey("assume_role_session_name").Pattern)
+
+// rateLimitPattern matches the decimal rates accepted for api_rate_limit. strconv.ParseFloat alone
+// would also accept signs, exponents, hexadecimal and infinities.
+var rateLimitPattern = regexp.MustCompile(configKey("api_rate_limit").Pattern)
+
+// digitsPattern matches the values of integer and seconds keys, before their bounds are checked.
+var digitsPattern = regexp.MustCompile(`^[0-9]+$`)
+
+// regionPattern matches AWS region names such as us-east-1, eu-central-2 or us-gov-west-1.
+var regionPattern = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-[0-9]+$`)
+
+// PluginConfig is the typed plugin configuration. Values the plugin itself defines, such as resource
+// types and severity signals, are only checked for syntax here and validated by the plugin.
+type PluginConfig struct {
+	Regions []string
+	Profile string
+
+	// AccessKeyID and SecretAccessKey, with the optional SessionToken, are static credentials used in
+	// place of the default credential chain. They are either both set or both empty.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// AssumeRoleChain is the ordered list of roles assumed before scanning, each hop using the
+	// credentials of the previous one. assume_role_arn is a chain of one. AssumeRoleAccountID is the
+	// account of the terminal role.
+	AssumeRoleChain     []string
+	AssumeRoleAccountID string
+	ExternalID          string
+	// AssumeRoleSessionName names every assumed role session. AssumeRoleDuration is zero to use the
+	// SDK's default session duration.
+	AssumeRoleSessionName string
+	AssumeRoleDuration    time.Duration
+
+	EndpointURL string
+	DisableSSL  bool
+
+	VpcIDs         []string
+	GroupIDs       []string
+	SkipDefaultVpc bool
+	TagFilters     map[string]string
+	// Discovery is how security groups are found, DiscoveryRegionScan or DiscoveryResourceGroups.
+	Discovery   string
+	LabelTags   []string
+	LabelPrefix string
+
+	UseSecurityGroupRulesAPI bool
+	RequireIngressRules      bool
+	SkipDefaultGroup         bool
+	// ReportPrivateIPs adds the private IPs of the interfaces using each security group to its evidence.
+	ReportPrivateIPs bool
+	// FlatEvidence adds a normalized, flat representation of each security group alongside the raw one.
+	FlatEvidence bool
+
+	// IncludeStates maps a resource type to the states its resources are evaluated in. States under the
+	// empty key apply to every type without states of its own. Types absent from both are evaluated in
+	// any state.
+	IncludeStates map[string][]string
+
+	// SensitivePorts is nil when sensitive_ports is not configured, and empty when it is configured
+	// empty to disable the check.
+	SensitivePorts []int32
+	ApprovedCIDRs  []netip.Prefix
+	// SeverityLevels maps exposure signals to severities, as configured by severity_levels.
+	SeverityLevels map[string]string
+
+	MaxRetries        int
+	MaxConcurrency    int
+	EvalTimeout       time.Duration
+	AWSRequestTimeout time.Duration
+	// APIRateLimit is the maximum number of AWS requests per second, or zero for no limit.
+	APIRateLimit      float64
+	EvidenceBatchSize int
+	// MaxResources caps the number of resources evaluated by a run, or is zero for no cap.
+	MaxResources int
+
+	DryRun       bool
+	DebugDumpDir string
+	// AWSSDKDebug logs every AWS request, response and retry.
+	AWSSDKDebug bool
+
+	// Resources lists the configured resource types, and is empty when every type is collected.
+	Resources []string
+	LogLevel  hclog.Level
+}
+
+// ParseConfig parses, validates and applies defaults to the raw plugin configuration. Errors name the
+// offending key. Integers, numbers, booleans, choices and patterned strings are read exactly as the
+// configuration schema describes them, without trimming, while lists and other strings are trimmed.
+func ParseConfig(raw map[string]string) (PluginConfig, error) {
+	cfg := PluginConfig{
+		MaxRetries:        DefaultMaxRetries,
+		MaxConcurrency:    DefaultMaxConcurrency,
+		EvalTimeout:       DefaultEvalTimeout,
+		AWSRequestTimeout: DefaultAWSRequestTimeout,
+		EvidenceBatchSize: DefaultEvidenceBatchSize,
+		LogLevel:          hclog.Info,
+	}
+	logLevel, err := parseChoice(raw, "log_level", "info")
+	if err != nil {
+		return cfg, err
+	}
+	cfg.LogLevel = hclog.LevelFromString(logLevel)
+
+	cfg.Regions = SplitList(raw["regions"])
+	for _, region := range cfg.Regions {
+		if !regionPattern.MatchString(region) {
+			return cfg, fmt.Errorf("invalid configuration: regions entry %q is not an AWS region, e.g. us-east-1", region)
+		}
+		if partition := RegionPartition(region); partition != RegionPartition(cfg.Regions[0]) {
+			return cfg, fmt.Errorf("invalid configuration: regions %q and %q are in different partitions, %s and %s", cfg.Regions[0], region, RegionPartition(cfg.Regions[0]), partition)
+		}
+	}
+	cfg.Profile = strings.TrimSpace(raw["profile"])
+
+	cfg.AccessKeyID = strings.TrimSpace(raw["aws_access_key_id"])
+	cfg.SecretAccessKey = strings.TrimSpace(raw["aws_secret_access_key"])
+	cfg.SessionToken = strings.TrimSpace(raw["aws_session_token"])
+	if (cfg.AccessKeyID == "") != (cfg.SecretAccessKey == "") || (cfg.SessionToken != "" && cfg.AccessKeyID == "") {
+		return cfg, fmt.Errorf("invalid configuration: aws_access_key_id and aws_secret_access_key must be set together, and aws_session_token only with them")
+	}
+	if cfg.AccessKeyID != "" && cfg.Profile != "" {
+		return cfg, fmt.Errorf("invalid configuration: aws_access_key_id and profile are mutually exclusive")
+	}
+
+	cfg.ExternalID = strings.TrimSpace(raw["external_id"])
+	cfg.AssumeRoleChain = SplitList(raw["assume_role_chain"])
+	if _, present := raw["assume_role_chain"]; present && len(cfg.AssumeRoleChain) == 0 {
+		return cfg, fmt.Errorf("invalid configuration: assume_role_chain must list at least one role ARN")
+	}
+	if assumeRoleArn := strings.TrimSpace(raw["assume_role_arn"]); assumeRoleArn != "" {
+		if len(cfg.AssumeRoleChain) > 0 {
+			return cfg, fmt.Errorf("invalid configuration: assume_role_arn and assume_role_chain are mutually exclusive")
+		}
+		cfg.AssumeRoleChain = []string{assumeRoleArn}
+	}
+	for _, roleArn := range cfg.AssumeRoleChain {
+		parsed, err := arn.Parse(roleArn)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid configuration: role ARN %q is not a valid ARN: %w", roleArn, err)
+		}
+		if parsed.Service != "iam" || !strings.HasPrefix(parsed.Resource, "role/") {
+			return cfg, fmt.Errorf("invalid configuration: role ARN %q is not an IAM role ARN", roleArn)
+		}
+		if len(cfg.Regions) > 0 && parsed.Partition != RegionPartition(cfg.Regions[0]) {
+			return cfg, fmt.Errorf("invalid configuration: role ARN %q is in partition %s but regions are in %s", roleArn, parsed.Partition, RegionPartition(cfg.Regions[0]))
+		}
+		// The terminal role determines the account that is scanned.
+		cfg.AssumeRoleAccountID = parsed.AccountID
+	}
+
+	cfg.AssumeRoleSessionName = DefaultAssumeRoleSessionName
+	if value := raw["assume_role_session_name"]; value != "" {
+		if !sessionNamePattern.MatchString(value) {
+			return cfg, fmt.Errorf("invalid configuration: assume_role_session_name %q must be 2 to 64 letters, digits or any of _+=,.@-", value)
+		}
+		cfg.AssumeRoleSessionName = value
+	}
+	// STS accepts sessions of 15 minutes to 12 hours, although each role may allow less than 12 hours.
+	if seconds, ok, err := parseInteger(raw, "assume_role_duration_seconds"); err != nil {
+		return cfg, err
+	} else if ok {
+		cfg.AssumeRoleDuration = time.Duration(seconds) * time.Second
+	}
+
+	cfg.EndpointURL = strings.TrimSpace(raw["endpoint_url"])
+	if cfg.EndpointURL != "" {
+		endpoint, err := url.Parse(cfg.EndpointURL)
+		if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+			return cfg, fmt.Errorf("invalid configuration: endpoint_url %q must be an absolute URL", cfg.EndpointURL)
+		}
+	}
+
+	cfg.VpcIDs = SplitList(raw["vpc_ids"])
+	for _, vpcID := range cfg.VpcIDs {
+		if !strings.HasPrefix(vpcID, "vpc-") {
+			return cfg, fmt.Errorf("invalid configuration: vpc_ids entry %q is not a VPC ID", vpcID)
+		}
+	}
+
+	cfg.GroupIDs = SplitList(raw["group_ids"])
+	for _, groupID := range cfg.GroupIDs {
+		if !strings.HasPrefix(groupID, "sg-") {
+			return cfg, fmt.Errorf("invalid configuration: group_ids entry %q is not a security group ID", groupID)
+		}
+	}
+
+	if cfg.TagFilters, err = parsePairs(raw, "tag_filters", "key=value"); err != nil {
+		return cfg, err
+	}
+	if cfg.Discovery, err = parseChoice(raw, "discovery", DiscoveryRegionScan); err != nil {
+		return cfg, err
+	}
+	if cfg.Discovery == DiscoveryResourceGroups && len(cfg.TagFilters) == 0 {
+		return cfg, fmt.Errorf("invalid configuration: discovery %s requires tag_filters", DiscoveryResourceGroups)
+	}
+	cfg.LabelTags = SplitList(raw["label_tags"])
+	cfg.LabelPrefix = strings.Trim(strings.TrimSpace(raw["label_prefix"]), "/")
+
+	cfg.IncludeStates = map[string][]string{}
+	for _, entry := range SplitList(raw["include_states"]) {
+		resource, state, found := strings.Cut(entry, ":")
+		if !found {
+			resource, state = "", entry
+		}
+		resource, state = strings.TrimSpace(resource), strings.ToLower(strings.TrimSpace(state))
+		if state == "" || (found && resource == "") {
+			return cfg, fmt.Errorf("invalid configuration: include_states entry %q must be a state or of the form resource:state", entry)
+		}
+		cfg.IncludeStates[resource] = append(cfg.IncludeStates[resource], state)
+	}
+
+	for key, target := range map[string]*bool{
+		"disable_ssl":                  &cfg.DisableSSL,
+		"skip_default_vpc":             &cfg.SkipDefaultVpc,
+		"use_security_group_rules_api": &cfg.UseSecurityGroupRulesAPI,
+		"require_ingress_rules":        &cfg.RequireIngressRules,
+		"skip_default_group":           &cfg.SkipDefaultGroup,
+		"report_private_ips":           &cfg.ReportPrivateIPs,
+		"flat_evidence":                &cfg.FlatEvidence,
+		"dry_run":                      &cfg.DryRun,
+		"aws_sdk_debug":                &cfg.AWSSDKDebug,
+	} {
+		if value := raw[key]; value != "" {
+			if *target, err = strconv.ParseBool(value); err != nil {
+				return cfg, fmt.Errorf("invalid configuration: %s %q is not a boolean: %w", key, value, err)
+			}
+		}
+	}
+	// Skipping verification is only meant for self-signed local endpoints, never for AWS itself.
+	if cfg.DisableSSL && cfg.EndpointURL == "" {
+		return cfg, fmt.Errorf("invalid configuration: disable_ssl requires endpoint_url")
+	}
+
+	if _, present := raw["sensitive_ports"]; present {
+		cfg.SensitivePorts = []int32{}
+		for _, value := range SplitList(raw["sensitive_ports"]) {
+			port, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || port < 0 || port > 65535 {
+				return cfg, fmt.Errorf("invalid configuration: sensitive_ports entry %q is not a port number", value)
+			}
+			cfg.SensitivePorts = append(cfg.SensitivePorts, int32(port))
+		}
+	}
+
+	cfg.ApprovedCIDRs = []netip.Prefix{}
+	for _, value := range SplitList(raw["approved_cidrs"]) {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid configuration: approved_cidrs entry %q is not a CIDR: %w", value, err)
+		}
+		cfg.ApprovedCIDRs = append(cfg.ApprovedCIDRs, prefix.Masked())
+	}
+
+	// Signals and severities are defined by the plugin, which reports malformed entries along with the
+	// accepted values.
+	cfg.SeverityLevels = map[string]string{}
+	for _, pair := range SplitList(raw["severity_levels"]) {
+		signal, severity, _ := strings.Cut(pair, "=")
+		cfg.SeverityLevels[strings.TrimSpace(signal)] = strings.TrimSpace(severity)
+	}
+
+	for key, target := range map[string]*int{
+		"max_retries":         &cfg.MaxRetries,
+		"max_resources":       &cfg.MaxResources,
+		"max_concurrency":     &cfg.MaxConcurrency,
+		"evidence_batch_size": &cfg.EvidenceBatchSize,
+	} {
+		if n, ok, err := parseInteger(raw, key); err != nil {
+			return cfg, err
+		} else if ok {
+			*target = n
+		}
+	}
+	for key, target := range map[string]*time.Duration{
+		"eval_timeout":        &cfg.EvalTimeout,
+		"aws_request_timeout": &cfg.AWSRequestTimeout,
+	} {
+		if seconds, ok, err := parseInteger(raw, key); err != nil {
+			return cfg, err
+		} else if ok {
+			*target = time.Duration(seconds) * time.Second
+		}
+	}
+	if value := raw["api_rate_limit"]; value != "" {
+		if cfg.APIRateLimit, err = strconv.ParseFloat(value, 64); err != nil || !rateLimitPattern.MatchString(value) {
+			return cfg, fmt.Errorf("invalid configuration: api_rate_limit %q must be a positive number of requests per second", value)
+		}
+	}
+
+	cfg.DebugDumpDir = strings.TrimSpace(raw["debug_dump_dir"])
+	cfg.Resources = SplitList(raw["resources"])
+
+	return cfg, nil
+}
+
+// parsePairs parses the comma-separated list of key=value pairs configured for key, where form
+// describes the expected entries in errors.
+func parsePairs(raw map[string]string, key string, form string) (map[string]string, error) {
+	pairs := map[string]string{}
+	for _, pair := range SplitList(raw[key]) {
+		name, value, found := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid configuration: %s entry %q must be of the form %s", key, pair, form)
+		}
+		pairs[name] = strings.TrimSpace(value)
+	}
+	return pairs, nil
+}
@@ -0,0 +1,227 @@
+package internal
+
+import (
+	"github.com/hashicorp/go-hclog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseConfigDefaults(t *testing.T) {
+	cfg, err := ParseConfig(map[string]string{})
+	if err != nil {
+		t.Fatalf("ParseConfig: %v", err)
+	}
+	if cfg.MaxRetries != DefaultMaxRetries || cfg.MaxConcurrency != DefaultMaxConcurrency || cfg.EvidenceBatchSize != DefaultEvidenceBatchSize {
+		t.Errorf("integer defaults = %d, %d, %d", cfg.MaxRetries, cfg.MaxConcurrency, cfg.EvidenceBatchSize)
+	}
+	if cfg.EvalTimeout != DefaultEvalTimeout || cfg.AWSRequestTimeout != DefaultAWSRequestTimeout {
+		t.Errorf("timeout defaults = %s, %s", cfg.EvalTimeout, cfg.AWSRequestTimeout)
+	}
+	if cfg.Discovery != DiscoveryRegionScan || cfg.LogLevel != hclog.Info || cfg.AssumeRoleSessionName != DefaultAssumeRoleSessionName {
+		t.Errorf("defaults = %q, %s, %q", cfg.Discovery, cfg.LogLevel, cfg.AssumeRoleSessionName)
+	}
+	// Unset sensitive_ports is told apart from an empty list, which disables the check.
+	if cfg.SensitivePorts != nil {
+		t.Errorf("SensitivePorts = %v, want nil", cfg.SensitivePorts)
+	}
+	if cfg.AssumeRoleDuration != 0 || cfg.APIRateLimit != 0 || cfg.MaxResources != 0 {
+		t.Errorf("unbounded defaults = %s, %v, %d", cfg.AssumeRoleDuration, cfg.APIRateLimit, cfg.MaxResources)
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		raw   map[string]string
+		check func(t *testing.T, cfg PluginConfig)
+	}{
+		{
+			name: "lists are trimmed and empty entries dropped",
+			raw:  map[string]string{"regions": " us-east-1 ,, us-west-2 ,", "vpc_ids": "vpc-1,", "label_tags": ""},
+			check: func(t *testing.T, cfg PluginConfig) {
+				if !slices.Equal(cfg.Regions, []string{"us-east-1", "us-west-2"}) || !slices.Equal(cfg.VpcIDs, []string{"vpc-1"}) || len(cfg.LabelTags) != 0 {
+					t.Errorf("lists = %q, %q, %q", cfg.Regions, cfg.VpcIDs, cfg.LabelTags)
+				}
+			},
+		},
+		{
+			name: "numbers accept their bounds",
+			raw:  map[string]string{"max_retries": "0", "max_concurrency": "1", "assume_role_duration_seconds": "43200", "eval_timeout": "1", "api_rate_limit": "0.5"},
+			check: func(t *testing.T, cfg PluginConfig) {
+				if cfg.MaxRetries != 0 || cfg.MaxConcurrency != 1 || cfg.AssumeRoleDuration != 12*time.Hour || cfg.EvalTimeout != time.Second || cfg.APIRateLimit != 0.5 {
+					t.Errorf("numbers = %d, %d, %s, %s, %v", cfg.MaxRetries, cfg.MaxConcurrency, cfg.AssumeRoleDuration, cfg.EvalTimeout, cfg.APIRateLimit)
+				}
+			},
+		},
+		{
+			name: "empty values keep the defaults",
+			raw:  map[string]string{"max_retries": "", "discovery": "", "log_level": ""},
+			check: func(t *testing.T, cfg PluginConfig) {
+				if cfg.MaxRetries != DefaultMaxRetries || cfg.Discovery != DiscoveryRegionScan || cfg.LogLevel != hclog.Info {
+					t.Errorf("values = %d, %q, %s", cfg.MaxRetries, cfg.Discovery, cfg.LogLevel)
+				}
+			},
+		},
+		{
+			name: "empty sensitive_ports disables the check",
+			raw:  map[string]string{"sensitive_ports": ""},
+			check: func(t *testing.T, cfg PluginConfig) {
+				if cfg.SensitivePorts == nil || len(cfg.SensitivePorts) != 0 {
+					t.Errorf("SensitivePorts = %v, want empty", cfg.SensitivePorts)
+				}
+			},
+		},
+		{
+			name: "assume_role_arn sets a single role chain",
+			raw:  map[string]string{"assume_role_arn": "arn:aws:iam::123456789012:role/scanner"},
+			check: func(t *testing.T, cfg PluginConfig) {
+				if !slices.Equal(cfg.AssumeRoleChain, []string{"arn:aws:iam::123456789012:role/scanner"}) || cfg.AssumeRoleAccountID != "123456789012" {
+					t.Errorf("chain = %q, account %q", cfg.AssumeRoleChain, cfg.AssumeRoleAccountID)
+				}
+			},
+		},
+		{
+			name: "the last role of a chain is the scanned account",
+			raw:  map[string]string{"assume_role_chain": "arn:aws:iam::111111111111:role/hub,arn:aws:iam::222222222222:role/scanner"},
+			check: func(t *testing.T, cfg PluginConfig) {
+				if cfg.AssumeRoleAccountID != "222222222222" {
+					t.Errorf("AssumeRoleAccountID = %q, want 222222222222", cfg.AssumeRoleAccountID)
+				}
+			},
+		},
+		{
+			name: "pairs and CIDRs are normalized",
+			raw:  map[string]string{"tag_filters": " team = network , env=", "approved_cidrs": "10.1.2.3/8", "label_prefix": "/aws/"},
+			check: func(t *testing.T, cfg PluginConfig) {
+				if len(cfg.TagFilters) != 2 || cfg.TagFilters["team"] != "network" || cfg.TagFilters["env"] != "" {
+					t.Errorf("TagFilters = %v", cfg.TagFilters)
+				}
+				if len(cfg.ApprovedCIDRs) != 1 || cfg.ApprovedCIDRs[0].String() != "10.0.0.0/8" {
+					t.Errorf("ApprovedCIDRs = %v", cfg.ApprovedCIDRs)
+				}
+				if cfg.LabelPrefix != "aws" {
+					t.Errorf("LabelPrefix = %q, want aws", cfg.LabelPrefix)
+				}
+			},
+		},
+		{
+			name: "include_states entries are global or per resource",
+			raw:  map[string]string{"include_states": "Available, nat-gateways:pending, nat-gateways:available"},
+			check: func(t *testing.T, cfg PluginConfig) {
+				if !slices.Equal(cfg.IncludeStates[""], []string{"available"}) || !slices.Equal(cfg.IncludeStates["nat-gateways"], []string{"pending", "available"}) {
+					t.Errorf("IncludeStates = %v", cfg.IncludeStates)
+				}
+			},
+		},
+		{
+			name: "booleans accept strconv forms",
+			raw:  map[string]string{"dry_run": "T", "skip_default_vpc": "1", "flat_evidence": "FALSE"},
+			check: func(t *testing.T, cfg PluginConfig) {
+				if !cfg.DryRun || !cfg.SkipDefaultVpc || cfg.FlatEvidence {
+					t.Errorf("booleans = %t, %t, %t", cfg.DryRun, cfg.SkipDefaultVpc, cfg.FlatEvidence)
+				}
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg, err := ParseConfig(test.raw)
+			if err != nil {
+				t.Fatalf("ParseConfig: %v", err)
+			}
+			test.check(t, cfg)
+		})
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     map[string]string
+		wantErr string
+	}{
+		{"region", map[string]string{"regions": "us-east"}, `regions entry "us-east" is not an AWS region`},
+		{"regions across partitions", map[string]string{"regions": "us-east-1,us-gov-west-1"}, "different partitions"},
+		{"secret key alone", map[string]string{"aws_secret_access_key": "secret"}, "must be set together"},
+		{"session token alone", map[string]string{"aws_session_token": "token"}, "must be set together"},
+		{"static credentials and profile", map[string]string{"aws_access_key_id": "AKID", "aws_secret_access_key": "secret", "profile": "dev"}, "mutually exclusive"},
+		{"empty role chain", map[string]string{"assume_role_chain": " , "}, "at least one role ARN"},
+		{"role ARN and chain", map[string]string{"assume_role_arn": "arn:aws:iam::1:role/a", "assume_role_chain": "arn:aws:iam::1:role/b"}, "mutually exclusive"},
+		{"not an ARN", map[string]string{"assume_role_arn": "scanner"}, "is not a valid ARN"},
+		{"not a role ARN", map[string]string{"assume_role_arn": "arn:aws:iam::123456789012:user/scanner"}, "is not an IAM role ARN"},
+		{"role in another partition", map[string]string{"regions": "cn-north-1", "assume_role_arn": "arn:aws:iam::123456789012:role/scanner"}, "is in partition aws but regions are in aws-cn"},
+		{"short session name", map[string]string{"assume_role_session_name": "a"}, "must be 2 to 64"},
+		{"session name characters", map[string]string{"assume_role_session_name": "scan run"}, "must be 2 to 64"},
+		{"session below the minimum", map[string]string{"assume_role_duration_seconds": "899"}, "must be a number of seconds from 900 to 43200"},
+		{"session above the maximum", map[string]string{"assume_role_duration_seconds": "43201"}, "must be a number of seconds from 900 to 43200"},
+		{"relative endpoint", map[string]string{"endpoint_url": "localhost:4566"}, "must be an absolute URL"},
+		{"disable_ssl without endpoint", map[string]string{"disable_ssl": "true"}, "disable_ssl requires endpoint_url"},
+		{"VPC ID", map[string]string{"vpc_ids": "vpc-1,subnet-1"}, `vpc_ids entry "subnet-1"`},
+		{"group ID", map[string]string{"group_ids": "web"}, `group_ids entry "web"`},
+		{"tag filter without value", map[string]string{"tag_filters": "team"}, "must be of the form key=value"},
+		{"tag filter without key", map[string]string{"tag_filters": "=network"}, "must be of the form key=value"},
+		{"discovery", map[string]string{"discovery": "everything"}, "must be one of region-scan or resource-groups"},
+		{"discovery is case-sensitive", map[string]string{"discovery": "Region-Scan"}, "must be one of"},
+		{"resource groups without tag filters", map[string]string{"discovery": "resource-groups"}, "requires tag_filters"},
+		{"include_states without state", map[string]string{"include_states": "nat-gateways:"}, "must be a state or of the form resource:state"},
+		{"include_states without resource", map[string]string{"include_states": ":available"}, "must be a state or of the form resource:state"},
+		{"boolean", map[string]string{"dry_run": "yes"}, "is not a boolean"},
+		{"boolean with whitespace", map[string]string{"dry_run": " true"}, "is not a boolean"},
+		{"port", map[string]string{"sensitive_ports": "22,65536"}, `sensitive_ports entry "65536"`},
+		{"CIDR", map[string]string{"approved_cidrs": "10.0.0.0"}, "is not a CIDR"},
+		{"negative retries", map[string]string{"max_retries": "-1"}, "max_retries \"-1\" must be a non-negative integer"},
+		{"signed retries", map[string]string{"max_retries": "+5"}, "must be a non-negative integer"},
+		{"retries with whitespace", map[string]string{"max_retries": " 5 "}, "must be a non-negative integer"},
+		{"blank retries", map[string]string{"max_retries": " "}, "must be a non-negative integer"},
+		{"zero concurrency", map[string]string{"max_concurrency": "0"}, "must be a positive integer"},
+		{"fractional batch size", map[string]string{"evidence_batch_size": "1.5"}, "must be a positive integer"},
+		{"zero timeout", map[string]string{"eval_timeout": "0"}, "must be a positive number of seconds"},
+		{"duration string", map[string]string{"aws_request_timeout": "30s"}, "must be a positive number of seconds"},
+		{"zero rate", map[string]string{"api_rate_limit": "0"}, "must be a positive number"},
+		{"NaN rate", map[string]string{"api_rate_limit": "NaN"}, "must be a positive number"},
+		{"infinite rate", map[string]string{"api_rate_limit": "+Inf"}, "must be a positive number"},
+		{"rate exponent", map[string]string{"api_rate_limit": "1e3"}, "must be a positive number"},
+		{"session name with whitespace", map[string]string{"assume_role_session_name": " scanner "}, "must be 2 to 64"},
+		{"log level", map[string]string{"log_level": "verbose"}, "must be one of trace, debug, info, warn or error"},
+		{"log level with whitespace", map[string]string{"log_level": "info "}, "must be one of"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseConfig(test.raw)
+			if err == nil {
+				t.Fatalf("ParseConfig succeeded, want an error containing %q", test.wantErr)
+			}
+			if !strings.Contains(err.Error(), test.wantErr) || !strings.HasPrefix(err.Error(), "invalid configuration: ") {
+				t.Errorf("error = %q, want an invalid configuration error containing %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigKeysAreUnique(t *testing.T) {
+	seen := map[string]bool{}
+	for _, key := range ConfigKeys {
+		if seen[key.Name] {
+			t.Errorf("%s is listed twice", key.Name)
+		}
+		seen[key.Name] = true
+		if key.Description == "" {
+			t.Errorf("%s has no description", key.Name)
+		}
+		if !IsConfigKey(key.Name) {
+			t.Errorf("IsConfigKey(%q) = false", key.Name)
+		}
+	}
+	if IsConfigKey("region") {
+		t.Error(`IsConfigKey("region") = true`)
+	}
+}
+
+func TestOmitOperationalConfig(t *testing.T) {
+	got := OmitOperationalConfig(map[string]string{"regions": "us-east-1", "log_level": "debug", "max_concurrency": "2", "unknown": "x"})
+	if len(got) != 2 || got["regions"] != "us-east-1" || got["unknown"] != "x" {
+		t.Errorf("OmitOperationalConfig = %v, want regions and unknown only", got)
+	}
+}
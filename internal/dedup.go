@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/compliance-framework/agent/runner/proto"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// EvidenceIdentity returns the stable identity of evidence. It is the UUID, which the plugin seeds from
+// the evidence labels, so a record produced by more than one region with the same labels has a single
+// identity. Evidence without a UUID is identified by its title and labels, quoted so that values holding
+// separators cannot collide.
+func EvidenceIdentity(evidence *proto.Evidence) string {
+	if evidence.GetUUID() != "" {
+		return evidence.GetUUID()
+	}
+	var identity strings.Builder
+	identity.WriteString(strconv.Quote(evidence.GetTitle()))
+	for _, key := range slices.Sorted(maps.Keys(evidence.GetLabels())) {
+		fmt.Fprintf(&identity, "\n%q=%q", key, evidence.GetLabels()[key])
+	}
+	return identity.String()
+}
+
+// Dedup returns evidence without the records whose identity, see EvidenceIdentity, is in seen or
+// repeats that of an earlier record. The first record of each identity is kept, order is preserved, and
+// the identities kept are added to seen, so it can be carried across calls.
+func Dedup(evidence []*proto.Evidence, seen map[string]bool) []*proto.Evidence {
+	result := make([]*proto.Evidence, 0, len(evidence))
+	for _, record := range evidence {
+		identity := EvidenceIdentity(record)
+		if seen[identity] {
+			continue
+		}
+		seen[identity] = true
+		result = append(result, record)
+	}
+	return result
+}
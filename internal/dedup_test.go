@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"github.com/compliance-framework/agent/runner/proto"
+	"testing"
+)
+
+func TestEvidenceIdentity(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   *proto.Evidence
+		sameID bool
+	}{
+		{
+			name:   "same UUID",
+			a:      &proto.Evidence{UUID: "1", Title: "a"},
+			b:      &proto.Evidence{UUID: "1", Title: "b"},
+			sameID: true,
+		},
+		{
+			name: "different UUIDs",
+			a:    &proto.Evidence{UUID: "1"},
+			b:    &proto.Evidence{UUID: "2"},
+		},
+		{
+			name:   "no UUID, same title and labels",
+			a:      &proto.Evidence{Title: "a", Labels: map[string]string{"region": "us-east-1", "type": "vpc"}},
+			b:      &proto.Evidence{Title: "a", Labels: map[string]string{"type": "vpc", "region": "us-east-1"}},
+			sameID: true,
+		},
+		{
+			name: "no UUID, different labels",
+			a:    &proto.Evidence{Title: "a", Labels: map[string]string{"region": "us-east-1"}},
+			b:    &proto.Evidence{Title: "a", Labels: map[string]string{"region": "eu-west-1"}},
+		},
+		{
+			name: "no UUID, different titles",
+			a:    &proto.Evidence{Title: "a"},
+			b:    &proto.Evidence{Title: "b"},
+		},
+		{
+			name: "label boundaries",
+			a:    &proto.Evidence{Labels: map[string]string{"a": "b\nc=d"}},
+			b:    &proto.Evidence{Labels: map[string]string{"a": "b", "c": "d"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if same := EvidenceIdentity(test.a) == EvidenceIdentity(test.b); same != test.sameID {
+				t.Errorf("identities equal = %t, want %t", same, test.sameID)
+			}
+		})
+	}
+}
+
+func TestDedup(t *testing.T) {
+	seen := map[string]bool{"0": true}
+	evidence := []*proto.Evidence{{UUID: "0"}, {UUID: "1"}, {UUID: "2"}, {UUID: "1", Title: "repeat"}, {UUID: "3"}}
+
+	result := Dedup(evidence, seen)
+	if got := uuids(result); got != "1,2,3" {
+		t.Errorf("Dedup kept %s, want 1,2,3", got)
+	}
+	if result[0].GetTitle() != "" {
+		t.Errorf("Dedup kept the repeat of 1 rather than the first record")
+	}
+	for _, uuid := range []string{"0", "1", "2", "3"} {
+		if !seen[uuid] {
+			t.Errorf("seen is missing %s", uuid)
+		}
+	}
+	if got := Dedup(evidence, seen); len(got) != 0 {
+		t.Errorf("second Dedup kept %s, want nothing", uuids(got))
+	}
+}
+
+func uuids(evidence []*proto.Evidence) string {
+	result := ""
+	for i, record := range evidence {
+		if i > 0 {
+			result += ","
+		}
+		result += record.GetUUID()
+	}
+	return result
+}
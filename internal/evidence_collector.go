@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"sync"
+)
+
+// EvidenceCollector accumulates the evidence and errors of concurrent workers until they are sent.
+// The zero value is ready to use, and all methods are safe for concurrent use.
+type EvidenceCollector struct {
+	mu       sync.Mutex
+	evidence []*proto.Evidence
+	err      error
+	sent     int
+	// seen holds the identity of every record queued so far, whether it is still queued, was sent or
+	// was dropped with a failed batch.
+	seen map[string]bool
+}
+
+// Add queues evidence to be sent by the next Flush. Records sharing an identity with a record already
+// queued are left out, see Dedup, so each identity is sent once over the collector's lifetime.
+func (c *EvidenceCollector) Add(evidence []*proto.Evidence) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.seen == nil {
+		c.seen = map[string]bool{}
+	}
+	c.evidence = append(c.evidence, Dedup(evidence, c.seen)...)
+}
+
+// AddError records err, if not nil, alongside the errors already collected.
+func (c *EvidenceCollector) AddError(err error) {
+	if err == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = errors.Join(c.err, err)
+}
+
+// Err returns every error collected by AddError, joined, or nil when there were none.
+func (c *EvidenceCollector) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Flush sends all queued evidence through apiHelper in batches of batchSize. Batches that cannot be
+// sent are dropped, so they are not resent, and their errors returned.
+func (c *EvidenceCollector) Flush(ctx context.Context, apiHelper runner.ApiHelper, batchSize int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.send(ctx, apiHelper, max(batchSize, 1), 1)
+}
+
+// flushFull is Flush limited to complete batches, leaving a partial batch queued.
+func (c *EvidenceCollector) flushFull(ctx context.Context, apiHelper runner.ApiHelper, batchSize int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	batchSize = max(batchSize, 1)
+	return c.send(ctx, apiHelper, batchSize, batchSize)
+}
+
+// send sends the queued evidence in batches while at least minimum records are queued. The caller holds
+// c.mu, which also serialises calls to apiHelper.
+func (c *EvidenceCollector) send(ctx context.Context, apiHelper runner.ApiHelper, batchSize int, minimum int) error {
+	var err error
+	for len(c.evidence) >= minimum && len(c.evidence) > 0 {
+		batch := c.evidence[:min(batchSize, len(c.evidence))]
+		c.evidence = c.evidence[len(batch):]
+		if sendErr := apiHelper.CreateEvidence(ctx, batch); sendErr != nil {
+			err = errors.Join(err, sendErr)
+			continue
+		}
+		c.sent += len(batch)
+	}
+	return err
+}
+
+// Sent returns the number of evidence records successfully sent so far.
+func (c *EvidenceCollector) Sent() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sent
+}
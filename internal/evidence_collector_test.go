@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"github.com/compliance-framework/agent/runner/proto"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingApiHelper records every batch of evidence sent to the agent.
+type recordingApiHelper struct {
+	mu      sync.Mutex
+	batches [][]*proto.Evidence
+	err     error
+}
+
+func (r *recordingApiHelper) CreateEvidence(_ context.Context, evidence []*proto.Evidence) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, evidence)
+	return r.err
+}
+
+func (r *recordingApiHelper) sent() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sent []*proto.Evidence
+	for _, batch := range r.batches {
+		sent = append(sent, batch...)
+	}
+	return uuids(sent)
+}
+
+func TestEvidenceCollectorDedupsAcrossBatches(t *testing.T) {
+	ctx := context.Background()
+	apiHelper := &recordingApiHelper{}
+	collector := &EvidenceCollector{}
+	helper := NewSynchronizedApiHelper(collector, apiHelper, 2)
+
+	// 1 and 2 fill a batch and are sent, 3 stays queued in a partial batch and 1 is a repeat of a sent
+	// record.
+	for _, batch := range [][]*proto.Evidence{{{UUID: "1"}, {UUID: "2"}}, {{UUID: "3"}, {UUID: "1"}}, {{UUID: "3"}}} {
+		if err := helper.CreateEvidence(ctx, batch); err != nil {
+			t.Fatalf("CreateEvidence: %v", err)
+		}
+	}
+	if err := helper.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if got := apiHelper.sent(); got != "1,2,3" {
+		t.Errorf("sent %s, want 1,2,3", got)
+	}
+	if collector.Sent() != 3 {
+		t.Errorf("Sent() = %d, want 3", collector.Sent())
+	}
+}
+
+// TestEvidenceCollectorConcurrentWriters is meant to be run with -race.
+func TestEvidenceCollectorConcurrentWriters(t *testing.T) {
+	ctx := context.Background()
+	apiHelper := &recordingApiHelper{}
+	collector := &EvidenceCollector{}
+	helper := NewSynchronizedApiHelper(collector, apiHelper, 7)
+
+	const writers, perWriter = 16, 50
+	var wg sync.WaitGroup
+	for writer := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perWriter {
+				// Every writer also sends a record shared by all of them, which must be sent once.
+				evidence := []*proto.Evidence{{UUID: fmt.Sprintf("%d-%d", writer, i)}, {UUID: "shared"}}
+				if err := helper.CreateEvidence(ctx, evidence); err != nil {
+					t.Errorf("CreateEvidence: %v", err)
+				}
+				if i%10 == 0 {
+					collector.AddError(fmt.Errorf("writer %d: error %d", writer, i))
+				}
+				_ = collector.Sent()
+			}
+		}()
+	}
+	wg.Wait()
+	if err := helper.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	want := writers*perWriter + 1
+	if collector.Sent() != want {
+		t.Errorf("Sent() = %d, want %d", collector.Sent(), want)
+	}
+	sent := map[string]int{}
+	for _, batch := range apiHelper.batches {
+		if len(batch) > 7 {
+			t.Errorf("batch of %d records, want at most 7", len(batch))
+		}
+		for _, record := range batch {
+			sent[record.GetUUID()]++
+		}
+	}
+	for uuid, count := range sent {
+		if count != 1 {
+			t.Errorf("%s sent %d times, want once", uuid, count)
+		}
+	}
+	if len(sent) != want {
+		t.Errorf("%d distinct records sent, want %d", len(sent), want)
+	}
+	if err := collector.Err(); err == nil || strings.Count(err.Error(), "writer ") != writers*perWriter/10 {
+		t.Errorf("Err() does not join the %d errors added: %v", writers*perWriter/10, err)
+	}
+}
@@ -0,0 +1,29 @@
+package internal
+
+import "strings"
+
+// partitionPrefixes maps region name prefixes to the AWS partition their regions belong to. Regions
+// matching none of them are in the standard aws partition.
+var partitionPrefixes = []struct {
+	prefix    string
+	partition string
+}{
+	{prefix: "us-gov-", partition: "aws-us-gov"},
+	{prefix: "cn-", partition: "aws-cn"},
+	{prefix: "us-isob-", partition: "aws-iso-b"},
+	{prefix: "us-iso-", partition: "aws-iso"},
+	{prefix: "eu-isoe-", partition: "aws-iso-e"},
+	{prefix: "us-isof-", partition: "aws-iso-f"},
+}
+
+// RegionPartition returns the partition of region, e.g. aws-us-gov for us-gov-west-1. The SDK resolves
+// endpoints per partition itself; this is used to check that regions and role ARNs agree, as
+// credentials are only valid within one partition.
+func RegionPartition(region string) string {
+	for _, p := range partitionPrefixes {
+		if strings.HasPrefix(region, p.prefix) {
+			return p.partition
+		}
+	}
+	return "aws"
+}
@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"regexp"
+	"strings"
+)
+
+// redactedValue replaces sensitive values in log output.
+const redactedValue = "********"
+
+// sensitiveConfigKeys are configuration keys whose values are never logged. Keys containing any of
+// sensitiveConfigKeyParts, e.g. secret_access_key, are treated the same way.
+var (
+	sensitiveConfigKeys     = []string{"external_id"}
+	sensitiveConfigKeyParts = []string{"secret", "token", "password"}
+)
+
+// accountIDPattern matches the 12 digit AWS account IDs found in labels, ARNs and other identifiers.
+var accountIDPattern = regexp.MustCompile(`\b([0-9]{4})[0-9]{8}\b`)
+
+// RedactConfig returns a copy of config that is safe to log: sensitive values are masked and account
+// IDs are truncated. The original map is left untouched.
+func RedactConfig(config map[string]string) map[string]string {
+	redacted := make(map[string]string, len(config))
+	for key, value := range config {
+		if value != "" && isSensitiveConfigKey(key) {
+			redacted[key] = redactedValue
+			continue
+		}
+		redacted[key] = RedactAccountIDs(value)
+	}
+	return redacted
+}
+
+// OmitSensitiveConfig returns a copy of config without its sensitive keys, so that it can be
+// fingerprinted without the fingerprint depending on, or revealing anything about, secret values.
+func OmitSensitiveConfig(config map[string]string) map[string]string {
+	result := make(map[string]string, len(config))
+	for key, value := range config {
+		if !isSensitiveConfigKey(key) {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// RedactAccountIDs truncates every account ID in value to its first four digits, e.g. 123456789012
+// becomes 1234********.
+func RedactAccountIDs(value string) string {
+	return accountIDPattern.ReplaceAllString(value, "${1}"+redactedValue)
+}
+
+func isSensitiveConfigKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveConfigKeys {
+		if key == sensitive {
+			return true
+		}
+	}
+	for _, part := range sensitiveConfigKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
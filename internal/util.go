@@ -1,5 +1,7 @@
 package internal
 
+import "strings"
+
 func MergeMaps(maps ...map[string]string) map[string]string {
 	result := make(map[string]string)
 	for _, imap := range maps {
@@ -13,3 +15,38 @@ func MergeMaps(maps ...map[string]string) map[string]string {
 func StringAddressed(str string) *string {
 	return &str
 }
+
+// SplitList splits a comma-separated config value into its trimmed, non-empty entries.
+func SplitList(value string) []string {
+	result := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// PrefixKeys returns a copy of labels with every key namespaced as `<prefix>/<key>`. Keys with a
+// leading underscore keep it in front of the prefix, e.g. `_vpc-id` becomes `_<prefix>/vpc-id`, so
+// their conventional meaning is preserved. An empty prefix returns the labels unchanged.
+func PrefixKeys(labels map[string]string, prefix string) map[string]string {
+	if prefix == "" {
+		return labels
+	}
+	result := make(map[string]string, len(labels))
+	for k, v := range labels {
+		if rest, found := strings.CutPrefix(k, "_"); found {
+			result["_"+prefix+"/"+rest] = v
+		} else {
+			result[prefix+"/"+k] = v
+		}
+	}
+	return result
+}
+
+// PortInRange reports whether port lies within the inclusive range from-to, as used by security
+// group and network ACL rules.
+func PortInRange(from, to, port int32) bool {
+	return from <= port && port <= to
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"iter"
+	"slices"
+	"strconv"
+)
+
+var internetGatewayComponent = &proto.Component{
+	Identifier:  "common-components/amazon-internet-gateway",
+	Type:        "service",
+	Title:       "Amazon VPC Internet Gateways",
+	Description: "Amazon VPC internet gateways connect a VPC to the internet. Once attached, any subnet whose route table sends traffic to the gateway can reach, and be reached from, the internet.",
+	Purpose:     "To make the internet boundary of each VPC explicit and auditable, so that networks intended to be isolated can be shown to have no path to the internet.",
+}
+
+func (l *CompliancePlugin) evalInternetGateways(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	input := &ec2.DescribeInternetGatewaysInput{}
+	if len(l.config.VpcIDs) > 0 {
+		input.Filters = []types.Filter{
+			{
+				Name:   aws.String("attachment.vpc-id"),
+				Values: l.config.VpcIDs,
+			},
+		}
+	}
+
+	found := 0
+	gatewayVpcs := map[string][]string{}
+	for gateway, err := range getInternetGateways(ctx, scan.client, input) {
+		if err != nil {
+			l.logger.Error("unable to get internet gateway", "region", scan.region, "error", err)
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+			continue
+		}
+		// Gateways attached only to excluded VPCs are out of scope; detached gateways are always reported.
+		if len(gateway.Attachments) > 0 && !slices.ContainsFunc(gateway.Attachments, func(attachment types.InternetGatewayAttachment) bool {
+			return !scan.excludesVpc(aws.ToString(attachment.VpcId))
+		}) {
+			continue
+		}
+		found++
+
+		gatewayID := aws.ToString(gateway.InternetGatewayId)
+		for _, attachment := range gateway.Attachments {
+			if isInternetGatewayAttached(attachment) {
+				vpcID := aws.ToString(attachment.VpcId)
+				gatewayVpcs[vpcID] = append(gatewayVpcs[vpcID], gatewayID)
+			}
+		}
+
+		labels := internal.MergeMaps(scan.labels, l.tagLabels(gateway.Tags), map[string]string{
+			"type":                "internet-gateway",
+			"internet-gateway-id": gatewayID,
+		})
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-internet-gateway/%s", gatewayID),
+			Type:       "network",
+			Title:      fmt.Sprintf("Amazon Internet Gateway [%s]", gatewayID),
+			Props: slices.Concat([]*proto.Property{
+				{
+					Name:  "internet-gateway-id",
+					Value: gatewayID,
+				},
+				{
+					Name:  "owner-id",
+					Value: aws.ToString(gateway.OwnerId),
+				},
+				{
+					Name:  "attached",
+					Value: strconv.FormatBool(slices.ContainsFunc(gateway.Attachments, isInternetGatewayAttached)),
+				},
+			}, internetGatewayAttachmentProperties(gateway.Attachments), tagProperties(gateway.Tags)),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, internetGatewayComponent, inventory, collectionActivities("internet gateway", "DescribeInternetGateways"), gateway); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	// VPC internet egress is only derived from a complete listing, otherwise a VPC could be wrongly
+	// reported as having no internet gateway.
+	if accumulatedErrors == nil {
+		scan.internetGatewayVpcs = gatewayVpcs
+	}
+
+	if found == 0 && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "internet-gateway", "internet gateways", collectionActivities("internet gateway", "DescribeInternetGateways"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+// isInternetGatewayAttached reports whether an attachment connects the gateway to its VPC. EC2 reports
+// attached internet gateways as `available` rather than `attached`, so both are accepted.
+func isInternetGatewayAttached(attachment types.InternetGatewayAttachment) bool {
+	return attachment.State == types.AttachmentStatusAttached || attachment.State == "available"
+}
+
+func internetGatewayAttachmentProperties(attachments []types.InternetGatewayAttachment) []*proto.Property {
+	props := make([]*proto.Property, 0, len(attachments)*2)
+	for index, attachment := range attachments {
+		props = append(props,
+			&proto.Property{
+				Name:  fmt.Sprintf("attachment/%d/vpc-id", index),
+				Value: aws.ToString(attachment.VpcId),
+			},
+			&proto.Property{
+				Name:  fmt.Sprintf("attachment/%d/state", index),
+				Value: string(attachment.State),
+			},
+		)
+	}
+	return props
+}
+
+// internetEgressLabels labels VPC evidence with whether an attached internet gateway makes internet
+// egress possible. Nothing is returned when the internet gateways could not be described.
+func internetEgressLabels(scan *regionScan, vpcID string) map[string]string {
+	if scan.internetGatewayVpcs == nil {
+		return nil
+	}
+	return map[string]string{
+		"internet-egress": strconv.FormatBool(len(scan.internetGatewayVpcs[vpcID]) > 0),
+	}
+}
+
+func getInternetGateways(ctx context.Context, client NetworkingAPI, input *ec2.DescribeInternetGatewaysInput) iter.Seq2[types.InternetGateway, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeInternetGatewaysOutput, *ec2.Options] {
+		return ec2.NewDescribeInternetGatewaysPaginator(client, input)
+	}, func(page *ec2.DescribeInternetGatewaysOutput) []types.InternetGateway {
+		return page.InternetGateways
+	})
+}
@@ -2,197 +2,804 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/ram"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	policyManager "github.com/compliance-framework/agent/policy-manager"
 	"github.com/compliance-framework/agent/runner"
 	"github.com/compliance-framework/agent/runner/proto"
 	"github.com/compliance-framework/plugin-aws-networking-security/internal"
 	"github.com/hashicorp/go-hclog"
 	goplugin "github.com/hashicorp/go-plugin"
-	"iter"
+	"golang.org/x/time/rate"
+	"google.golang.org/protobuf/encoding/protojson"
+	"maps"
+	"net/http"
 	"os"
 	"slices"
+	"strings"
+	"sync"
 )
 
+// NetworkingAPI is the subset of the EC2 API used by the plugin. It is satisfied by *ec2.Client and
+// allows the whole evaluation to be exercised against a fake, see internal/awsmock.
+type NetworkingAPI interface {
+	DescribeSecurityGroups(context.Context, *ec2.DescribeSecurityGroupsInput, ...func(*ec2.Options)) (*ec2.DescribeSecurityGroupsOutput, error)
+	DescribeNetworkAcls(context.Context, *ec2.DescribeNetworkAclsInput, ...func(*ec2.Options)) (*ec2.DescribeNetworkAclsOutput, error)
+	DescribeVpcs(context.Context, *ec2.DescribeVpcsInput, ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error)
+	DescribeFlowLogs(context.Context, *ec2.DescribeFlowLogsInput, ...func(*ec2.Options)) (*ec2.DescribeFlowLogsOutput, error)
+	DescribeSubnets(context.Context, *ec2.DescribeSubnetsInput, ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error)
+	DescribeManagedPrefixLists(context.Context, *ec2.DescribeManagedPrefixListsInput, ...func(*ec2.Options)) (*ec2.DescribeManagedPrefixListsOutput, error)
+	GetManagedPrefixListEntries(context.Context, *ec2.GetManagedPrefixListEntriesInput, ...func(*ec2.Options)) (*ec2.GetManagedPrefixListEntriesOutput, error)
+	DescribeNetworkInterfaces(context.Context, *ec2.DescribeNetworkInterfacesInput, ...func(*ec2.Options)) (*ec2.DescribeNetworkInterfacesOutput, error)
+	DescribeRouteTables(context.Context, *ec2.DescribeRouteTablesInput, ...func(*ec2.Options)) (*ec2.DescribeRouteTablesOutput, error)
+	DescribeNatGateways(context.Context, *ec2.DescribeNatGatewaysInput, ...func(*ec2.Options)) (*ec2.DescribeNatGatewaysOutput, error)
+	DescribeInternetGateways(context.Context, *ec2.DescribeInternetGatewaysInput, ...func(*ec2.Options)) (*ec2.DescribeInternetGatewaysOutput, error)
+	DescribeVpcEndpoints(context.Context, *ec2.DescribeVpcEndpointsInput, ...func(*ec2.Options)) (*ec2.DescribeVpcEndpointsOutput, error)
+	DescribeTransitGateways(context.Context, *ec2.DescribeTransitGatewaysInput, ...func(*ec2.Options)) (*ec2.DescribeTransitGatewaysOutput, error)
+	DescribeTransitGatewayAttachments(context.Context, *ec2.DescribeTransitGatewayAttachmentsInput, ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayAttachmentsOutput, error)
+	DescribeVpcPeeringConnections(context.Context, *ec2.DescribeVpcPeeringConnectionsInput, ...func(*ec2.Options)) (*ec2.DescribeVpcPeeringConnectionsOutput, error)
+	DescribeDhcpOptions(context.Context, *ec2.DescribeDhcpOptionsInput, ...func(*ec2.Options)) (*ec2.DescribeDhcpOptionsOutput, error)
+	DescribeAddresses(context.Context, *ec2.DescribeAddressesInput, ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error)
+	DescribeVpnConnections(context.Context, *ec2.DescribeVpnConnectionsInput, ...func(*ec2.Options)) (*ec2.DescribeVpnConnectionsOutput, error)
+	DescribeCustomerGateways(context.Context, *ec2.DescribeCustomerGatewaysInput, ...func(*ec2.Options)) (*ec2.DescribeCustomerGatewaysOutput, error)
+	DescribeSecurityGroupRules(context.Context, *ec2.DescribeSecurityGroupRulesInput, ...func(*ec2.Options)) (*ec2.DescribeSecurityGroupRulesOutput, error)
+	DescribeClientVpnEndpoints(context.Context, *ec2.DescribeClientVpnEndpointsInput, ...func(*ec2.Options)) (*ec2.DescribeClientVpnEndpointsOutput, error)
+	DescribeNetworkInsightsPaths(context.Context, *ec2.DescribeNetworkInsightsPathsInput, ...func(*ec2.Options)) (*ec2.DescribeNetworkInsightsPathsOutput, error)
+	DescribeNetworkInsightsAnalyses(context.Context, *ec2.DescribeNetworkInsightsAnalysesInput, ...func(*ec2.Options)) (*ec2.DescribeNetworkInsightsAnalysesOutput, error)
+	DescribeEgressOnlyInternetGateways(context.Context, *ec2.DescribeEgressOnlyInternetGatewaysInput, ...func(*ec2.Options)) (*ec2.DescribeEgressOnlyInternetGatewaysOutput, error)
+	DescribeTrafficMirrorTargets(context.Context, *ec2.DescribeTrafficMirrorTargetsInput, ...func(*ec2.Options)) (*ec2.DescribeTrafficMirrorTargetsOutput, error)
+	DescribeTrafficMirrorSessions(context.Context, *ec2.DescribeTrafficMirrorSessionsInput, ...func(*ec2.Options)) (*ec2.DescribeTrafficMirrorSessionsOutput, error)
+	DescribeVpcEndpointServiceConfigurations(context.Context, *ec2.DescribeVpcEndpointServiceConfigurationsInput, ...func(*ec2.Options)) (*ec2.DescribeVpcEndpointServiceConfigurationsOutput, error)
+	DescribeVpcEndpointServicePermissions(context.Context, *ec2.DescribeVpcEndpointServicePermissionsInput, ...func(*ec2.Options)) (*ec2.DescribeVpcEndpointServicePermissionsOutput, error)
+}
+
+// TaggingAPI is the subset of the Resource Groups Tagging API used by the plugin. It is satisfied by
+// *resourcegroupstaggingapi.Client.
+type TaggingAPI interface {
+	GetResources(context.Context, *resourcegroupstaggingapi.GetResourcesInput, ...func(*resourcegroupstaggingapi.Options)) (*resourcegroupstaggingapi.GetResourcesOutput, error)
+}
+
+// SharingAPI is the subset of the AWS Resource Access Manager API used by the plugin. It is satisfied
+// by *ram.Client.
+type SharingAPI interface {
+	ListResources(context.Context, *ram.ListResourcesInput, ...func(*ram.Options)) (*ram.ListResourcesOutput, error)
+	GetResourceShareAssociations(context.Context, *ram.GetResourceShareAssociationsInput, ...func(*ram.Options)) (*ram.GetResourceShareAssociationsOutput, error)
+}
+
+// IdentityAPI is the subset of the STS API used by the plugin. It is satisfied by *sts.Client.
+type IdentityAPI interface {
+	GetCallerIdentity(context.Context, *sts.GetCallerIdentityInput, ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
 type CompliancePlugin struct {
 	logger hclog.Logger
-	config map[string]string
+
+	// newNetworkingClient, newIdentityClient, newTaggingClient and newSharingClient build the AWS
+	// clients for a region's config.
+	newNetworkingClient func(aws.Config) NetworkingAPI
+	newIdentityClient   func(aws.Config) IdentityAPI
+	newTaggingClient    func(aws.Config) TaggingAPI
+	newSharingClient    func(aws.Config) SharingAPI
+
+	// config is the typed configuration, see internal.ParseConfig.
+	config internal.PluginConfig
+
+	// configHash fingerprints the configuration, without sensitive or operational values, and labels
+	// all evidence.
+	configHash string
+
+	// rateLimiter bounds the combined rate of AWS requests made by all regions. It is nil when
+	// api_rate_limit is not configured.
+	rateLimiter *rate.Limiter
+
+	// resources holds the collection passes enabled by the resources config key.
+	resources map[string]bool
+}
+
+// NewCompliancePlugin returns a plugin that talks to AWS through the SDK clients.
+func NewCompliancePlugin(logger hclog.Logger) *CompliancePlugin {
+	return &CompliancePlugin{
+		logger: logger,
+		newNetworkingClient: func(cfg aws.Config) NetworkingAPI {
+			return ec2.NewFromConfig(cfg)
+		},
+		newIdentityClient: func(cfg aws.Config) IdentityAPI {
+			return sts.NewFromConfig(cfg)
+		},
+		newTaggingClient: func(cfg aws.Config) TaggingAPI {
+			return resourcegroupstaggingapi.NewFromConfig(cfg)
+		},
+		newSharingClient: func(cfg aws.Config) SharingAPI {
+			return ram.NewFromConfig(cfg)
+		},
+	}
 }
 
 func (l *CompliancePlugin) Configure(req *proto.ConfigureRequest) (*proto.ConfigureResponse, error) {
-	l.config = req.GetConfig()
-	return &proto.ConfigureResponse{}, nil
+	raw := req.GetConfig()
+	cfg, err := internal.ParseConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	// The level is applied first so the rest of configuration is logged at the requested verbosity.
+	l.logger.SetLevel(cfg.LogLevel)
+
+	l.logger.Debug("Configuring plugin", "config", internal.RedactConfig(raw))
+	for _, key := range slices.Sorted(maps.Keys(raw)) {
+		if !internal.IsConfigKey(key) {
+			l.logger.Warn("ignoring unknown configuration key", "key", key)
+		}
+	}
+
+	if cfg.Profile != "" {
+		if _, err := config.LoadSharedConfigProfile(context.TODO(), cfg.Profile); err != nil {
+			l.logger.Error("unable to resolve AWS profile", "profile", cfg.Profile, "error", err)
+			return nil, fmt.Errorf("invalid configuration: unable to resolve AWS profile %q: %w", cfg.Profile, err)
+		}
+	}
+
+	if cfg.SensitivePorts == nil {
+		cfg.SensitivePorts = defaultSensitivePorts
+	}
+
+	for signal, severity := range cfg.SeverityLevels {
+		if _, known := defaultSeverities[signal]; !known || !slices.Contains(severityLevels, severity) {
+			return nil, fmt.Errorf("invalid configuration: severity_levels entry %q must be of the form signal=severity, with a signal of %s and a severity of %s", signal+"="+severity, strings.Join(slices.Sorted(maps.Keys(defaultSeverities)), ", "), strings.Join(severityLevels, ", "))
+		}
+	}
+
+	resources := map[string]bool{}
+	names := cfg.Resources
+	if len(names) == 0 {
+		names = resourceNames()
+	}
+	for _, name := range names {
+		if !slices.Contains(resourceNames(), name) {
+			return nil, fmt.Errorf("invalid configuration: resources entry %q is not one of %s", name, strings.Join(resourceNames(), ", "))
+		}
+		resources[name] = true
+	}
+	for name := range cfg.IncludeStates {
+		if name != "" && !slices.Contains(resourceNames(), name) {
+			return nil, fmt.Errorf("invalid configuration: include_states resource %q is not one of %s", name, strings.Join(resourceNames(), ", "))
+		}
+	}
+
+	l.config = cfg
+	l.configHash = configFingerprint(internal.OmitOperationalConfig(internal.OmitSensitiveConfig(raw)))
+	l.resources = resources
+	l.rateLimiter = nil
+	if cfg.APIRateLimit > 0 {
+		l.rateLimiter = newRateLimiter(cfg.APIRateLimit)
+	}
+
+	// The capabilities echo is informational, so failing to encode them does not fail configuration.
+	value, err := capabilitiesJSON()
+	if err != nil {
+		l.logger.Warn("unable to encode plugin capabilities", "error", err)
+	}
+	return &proto.ConfigureResponse{Value: value}, nil
 }
 
 func (l *CompliancePlugin) Eval(request *proto.EvalRequest, apiHelper runner.ApiHelper) (*proto.EvalResponse, error) {
-	ctx := context.TODO()
+	evalTimeout := l.config.EvalTimeout
+	if evalTimeout <= 0 {
+		evalTimeout = internal.DefaultEvalTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), evalTimeout)
+	defer cancel()
+
 	evalStatus := proto.ExecutionStatus_SUCCESS
-	var accumulatedErrors error
+	summary := newRunSummary(l.config.MaxResources)
 
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(os.Getenv("AWS_REGION")))
+	regions, err := l.resolveRegions(ctx)
 	if err != nil {
-		l.logger.Error("unable to load SDK config", "error", err)
-		evalStatus = proto.ExecutionStatus_FAILURE
+		l.logger.Error("unable to determine the regions to scan", "error", err)
+		return &proto.EvalResponse{
+			Status: proto.ExecutionStatus_FAILURE,
+		}, err
+	}
+	l.logger.Info("Scanning regions", "regions", regions)
+
+	if err := l.preflight(ctx, regions[0]); err != nil {
+		l.logger.Error("preflight check failed, not scanning", "error", err)
+		return &proto.EvalResponse{
+			Status: proto.ExecutionStatus_FAILURE,
+		}, err
+	}
+
+	maxConcurrency := l.config.MaxConcurrency
+	if maxConcurrency < 1 {
+		maxConcurrency = internal.DefaultMaxConcurrency
+	}
+
+	if l.config.DryRun {
+		apiHelper = &dryRunApiHelper{logger: l.logger}
+	}
+
+	evidenceBatchSize := l.config.EvidenceBatchSize
+	if evidenceBatchSize < 1 {
+		evidenceBatchSize = internal.DefaultEvidenceBatchSize
+	}
+
+	// Regions are scanned concurrently, each with its own clients. Setting up a region and running each
+	// of its collection passes take one of max_concurrency workers shared by the whole run, so at most
+	// max_concurrency of them run at once across every region. Evidence and errors are accumulated by a
+	// shared collector, evidence being sent in batches as it is queued.
+	collector := &internal.EvidenceCollector{}
+	sharedApiHelper := internal.NewSynchronizedApiHelper(collector, apiHelper, evidenceBatchSize)
+	workers := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+
+	for _, region := range regions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := l.evalRegion(ctx, region, request, sharedApiHelper, summary, workers); err != nil {
+				collector.AddError(fmt.Errorf("region %s: %w", region, err))
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The last, partial batch is sent once every region has finished. Evidence collected before a
+	// cancellation or timeout is still sent, so flushing must not inherit the expired deadline.
+	flushCtx := context.WithoutCancel(ctx)
+	if err := sharedApiHelper.Flush(flushCtx); err != nil {
+		l.logger.Error("Failed to send evidences", "error", err)
+		collector.AddError(err)
+	}
+	sent := collector.Sent()
+	accumulatedErrors := collector.Err()
+	if summary.allPassesFailed() {
+		accumulatedErrors = errors.Join(errors.New("every collection pass failed"), accumulatedErrors)
+	}
+	if summary.wasTruncated() {
+		l.logger.Warn("Evaluation capped at max_resources, further resources were not evaluated", "max_resources", l.config.MaxResources)
+	}
+
+	// The summary is likewise sent even when the evaluation timed out.
+	if err := l.reportRunSummary(flushCtx, summary, regions, len(request.GetPolicyPaths()), accumulatedErrors, sharedApiHelper); err != nil {
+		accumulatedErrors = errors.Join(accumulatedErrors, err)
+	}
+	if err := sharedApiHelper.Flush(flushCtx); err != nil {
+		l.logger.Error("Failed to send evidences", "type", "run-summary", "error", err)
 		accumulatedErrors = errors.Join(accumulatedErrors, err)
 	}
 
-	client := ec2.NewFromConfig(cfg)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		l.logger.Error("Evaluation timed out", "timeout", evalTimeout)
+		return &proto.EvalResponse{
+			Status: proto.ExecutionStatus_FAILURE,
+		}, errors.Join(fmt.Errorf("evaluation timed out after %s", evalTimeout), accumulatedErrors)
+	}
 
-	// Run policy checks
-	for group, err := range getSecurityGroups(ctx, client) {
-		if err != nil {
-			l.logger.Error("unable to get instance", "error", err)
+	// Errors only fail the run outright when nothing could be collected. When some evidence was sent
+	// the run is a partial success: the status remains SUCCESS and the errors are still returned so
+	// the gaps are visible. Evidence the plugin reports about its own run, such as region scan statuses,
+	// does not count as collected: a run in which no pass ran, or every pass failed or was skipped, fails.
+	if accumulatedErrors != nil {
+		if sent == 0 || summary.nothingCollected() {
 			evalStatus = proto.ExecutionStatus_FAILURE
+		} else {
+			l.logger.Warn("Evaluation partially succeeded", "evidence-sent", sent, "error", accumulatedErrors)
+		}
+	}
+
+	return &proto.EvalResponse{
+		Status: evalStatus,
+	}, accumulatedErrors
+}
+
+// regionScan holds the client, base labels and lookups shared by the collection passes of a single
+// region.
+type regionScan struct {
+	region  string
+	client  NetworkingAPI
+	tagging TaggingAPI
+	sharing SharingAPI
+	labels  map[string]string
+
+	// prefixListCIDRs maps a managed prefix list ID to the CIDRs it contains.
+	prefixListCIDRs map[string][]string
+
+	// vpcs holds the in-scope VPCs of the region, indexed by VPC ID. It is nil when the VPCs could not
+	// be described.
+	vpcs map[string]types.Vpc
+
+	// excludedVpcs holds the IDs of VPCs whose resources are left out of the scan, see skip_default_vpc.
+	excludedVpcs map[string]bool
+
+	// summary aggregates the resources evaluated across the whole run.
+	summary *runSummary
+
+	// internetGatewayVpcs maps a VPC ID to the internet gateways attached to it. It is nil when the
+	// internet gateways could not be described.
+	internetGatewayVpcs map[string][]string
+}
+
+// collectionPass evaluates one resource type within a region.
+type collectionPass struct {
+	// resource is the name used to select the pass in the resources config key.
+	resource string
+	eval     func(context.Context, *regionScan, *proto.EvalRequest, runner.ApiHelper) error
+	// prerequisite passes record context on the regionScan for the passes after them. They run one at a
+	// time, before any other pass of the region is started.
+	prerequisite bool
+}
+
+// collectionPasses returns every supported pass in the order it is started within a region.
+// Prerequisite passes must come first.
+func (l *CompliancePlugin) collectionPasses() []collectionPass {
+	return []collectionPass{
+		// Prefix lists run first so security group rules referencing them can be cross-linked.
+		{resource: "managed-prefix-lists", eval: l.evalManagedPrefixLists, prerequisite: true},
+		// Internet gateways run before any pass that reports VPC context, so the VPC's internet egress is known.
+		{resource: "internet-gateways", eval: l.evalInternetGateways, prerequisite: true},
+		{resource: "security-groups", eval: l.evalSecurityGroups},
+		{resource: "network-acls", eval: l.evalNetworkACLs},
+		{resource: "flow-logs", eval: l.evalFlowLogs},
+		{resource: "subnets", eval: l.evalSubnets},
+		{resource: "route-tables", eval: l.evalRouteTables},
+		{resource: "nat-gateways", eval: l.evalNatGateways},
+		{resource: "vpc-endpoints", eval: l.evalVpcEndpoints},
+		{resource: "transit-gateways", eval: l.evalTransitGateways},
+		{resource: "vpc-peering-connections", eval: l.evalVpcPeeringConnections},
+		{resource: "dhcp-options", eval: l.evalDhcpOptions},
+		{resource: "elastic-ips", eval: l.evalElasticIPs},
+		{resource: "client-vpn-endpoints", eval: l.evalClientVpnEndpoints},
+		{resource: "vpn-connections", eval: l.evalVpnConnections},
+		{resource: "customer-gateways", eval: l.evalCustomerGateways},
+		{resource: "network-insights-paths", eval: l.evalNetworkInsightsPaths},
+		{resource: "egress-only-internet-gateways", eval: l.evalEgressOnlyInternetGateways},
+		{resource: "traffic-mirror-sessions", eval: l.evalTrafficMirrorSessions},
+		{resource: "vpc-endpoint-services", eval: l.evalVpcEndpointServices},
+	}
+}
+
+// resourceNames lists the names accepted by the resources config key.
+func resourceNames() []string {
+	passes := (&CompliancePlugin{}).collectionPasses()
+	names := make([]string, 0, len(passes))
+	for _, pass := range passes {
+		names = append(names, pass.resource)
+	}
+	return names
+}
+
+// excludesVpc reports whether resources in the VPC are out of scope.
+func (s *regionScan) excludesVpc(vpcID string) bool {
+	return s.excludedVpcs[vpcID]
+}
+
+// excludesState reports whether include_states leaves a resource of the pass resource out of evaluation
+// because of its state, counting it as filtered under resourceType. Passes opt in by calling it with the
+// state of each resource; the states of other passes are never checked.
+func (l *CompliancePlugin) excludesState(scan *regionScan, resource string, resourceType string, state string) bool {
+	states, ok := l.config.IncludeStates[resource]
+	if !ok {
+		states, ok = l.config.IncludeStates[""]
+	}
+	if !ok || slices.Contains(states, strings.ToLower(state)) {
+		return false
+	}
+	scan.summary.recordFiltered(resourceType, 1)
+	return true
+}
+
+// evalRegion collects and evaluates every supported resource type in a single region. workers is the
+// run's pool: a slot is held while the region is set up and while each pass runs.
+func (l *CompliancePlugin) evalRegion(ctx context.Context, region string, request *proto.EvalRequest, apiHelper runner.ApiHelper, summary *runSummary, workers chan struct{}) error {
+	var accumulatedErrors error
+
+	// Until the scan is set up, a failed region is reported with the region labels alone.
+	labels := map[string]string{
+		"provider":  "aws",
+		"partition": internal.RegionPartition(region),
+		"region":    region,
+	}
+
+	// A worker is never held while waiting for another, so regions cannot starve each other's passes.
+	// A region still waiting for one when the evaluation is cancelled or times out is not scanned.
+	if err := acquireWorker(ctx, workers); err != nil {
+		return errors.Join(err, l.reportScanStatus(ctx, region, labels, scanStatusFailed, 0, 0, err, apiHelper))
+	}
+	cfg, err := l.loadAWSConfig(ctx, region)
+	if err != nil {
+		<-workers
+		l.logger.Error("unable to load SDK config", "region", region, "error", err)
+		return errors.Join(err, l.reportScanStatus(ctx, region, labels, scanStatusFailed, 0, 0, err, apiHelper))
+	}
+
+	scan := &regionScan{
+		region:  region,
+		client:  l.newNetworkingClient(cfg),
+		tagging: l.newTaggingClient(cfg),
+		sharing: l.newSharingClient(cfg),
+		labels: map[string]string{
+			"provider":  "aws",
+			"partition": internal.RegionPartition(region),
+			"region":    region,
+		},
+		prefixListCIDRs: map[string][]string{},
+		summary:         summary,
+		excludedVpcs:    map[string]bool{},
+	}
+	if accountID := l.accountID(ctx, l.newIdentityClient(cfg), region); accountID != "" {
+		scan.labels["account-id"] = accountID
+	}
+
+	// VPCs are described once and shared by every pass that needs VPC context. Default VPCs can only be
+	// excluded once they are known, so a failure here leaves them in scope.
+	if err := l.loadVpcs(ctx, scan); err != nil {
+		if l.config.SkipDefaultVpc {
+			l.logger.Warn("unable to identify the default VPC, its resources will be scanned", "region", region)
+		}
+		accumulatedErrors = errors.Join(accumulatedErrors, err)
+	}
+	// Security group rules are cross-linked to the CIDRs of the prefix lists they reference, which the
+	// managed-prefix-lists pass records as it runs. Without that pass they are loaded here instead.
+	if l.resources["security-groups"] && !l.resources["managed-prefix-lists"] {
+		if err := l.loadPrefixListCIDRs(ctx, scan); err != nil {
+			l.logger.Warn("unable to get managed prefix lists, security group rules referencing them will have no CIDRs", "region", region, "error", err)
+		}
+	}
+	<-workers
+
+	// A pass denied by IAM is reported as skipped rather than failing the run, so the plugin can be run
+	// under roles that intentionally grant only some of the permissions.
+	var mu sync.Mutex
+	passes, failedPasses := 0, 0
+	runPass := func(pass collectionPass) {
+		err := pass.eval(ctx, scan, request, apiHelper)
+		skipped := err != nil && isAccessDenied(err)
+		scan.summary.recordPass(err, skipped)
+		failed := err != nil
+		if skipped {
+			l.logger.Warn("insufficient permissions, skipping collection", "region", region, "resource", pass.resource, "error", err)
+			err = l.reportCollectionSkipped(ctx, scan, pass.resource, err, apiHelper)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		passes++
+		if failed {
+			failedPasses++
+		}
+		accumulatedErrors = errors.Join(accumulatedErrors, err)
+	}
+
+	// Passes other than prerequisites only read the scan context, so they run concurrently on the run's
+	// workers. Their AWS requests share the api_rate_limit limiter.
+	var wg sync.WaitGroup
+	for _, pass := range l.collectionPasses() {
+		if !l.resources[pass.resource] {
+			continue
+		}
+		if scan.summary.capReached() {
+			break
+		}
+		// Once the evaluation is cancelled or times out, the remaining passes are not started, including
+		// one waiting for a worker.
+		if err := acquireWorker(ctx, workers); err != nil {
+			mu.Lock()
 			accumulatedErrors = errors.Join(accumulatedErrors, err)
+			mu.Unlock()
 			break
 		}
-
-		labels := map[string]string{
-			"provider": "aws",
-			"type":     "security-group",
-			"group-id": aws.ToString(group.GroupId),
-			"_vpc-id":  aws.ToString(group.VpcId),
+		if pass.prerequisite {
+			runPass(pass)
+			<-workers
+			continue
 		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+			runPass(pass)
+		}()
+	}
+	wg.Wait()
 
-		activities := make([]*proto.Activity, 0)
-		evidences := make([]*proto.Evidence, 0)
+	status := scanStatusSuccess
+	switch {
+	case passes > 0 && failedPasses == passes:
+		status = scanStatusFailed
+	case failedPasses > 0 || accumulatedErrors != nil:
+		status = scanStatusPartial
+	}
+	if err := l.reportScanStatus(ctx, region, scan.labels, status, passes, failedPasses, accumulatedErrors, apiHelper); err != nil {
+		accumulatedErrors = errors.Join(accumulatedErrors, err)
+	}
 
-		actors := []*proto.OriginActor{
-			{
-				Title: "The Continuous Compliance Framework",
-				Type:  "assessment-platform",
-				Links: []*proto.Link{
-					{
-						Href: "https://compliance-framework.github.io/docs/",
-						Rel:  internal.StringAddressed("reference"),
-						Text: internal.StringAddressed("The Continuous Compliance Framework"),
-					},
-				},
-			},
-			{
-				Title: "Continuous Compliance Framework - Local SSH Plugin",
-				Type:  "tool",
-				Links: []*proto.Link{
-					{
-						Href: "https://github.com/compliance-framework/plugin-local-ssh",
-						Rel:  internal.StringAddressed("reference"),
-						Text: internal.StringAddressed("The Continuous Compliance Framework' Local SSH Plugin"),
-					},
-				},
-			},
+	return accumulatedErrors
+}
+
+// acquireWorker takes one of the run's workers, or returns the context's error when it is done first.
+func acquireWorker(ctx context.Context, workers chan struct{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case workers <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// accountID resolves the account the region is scanned under. It is called once per region so STS is
+// not queried per resource. When the caller lacks sts:GetCallerIdentity it falls back to the account
+// of the assumed role, if any, and otherwise returns an empty string so the label is omitted.
+func (l *CompliancePlugin) accountID(ctx context.Context, client IdentityAPI, region string) string {
+	identity, err := client.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		l.logger.Warn("unable to resolve caller identity, omitting account-id label", "region", region, "error", err)
+		return l.config.AssumeRoleAccountID
+	}
+	return aws.ToString(identity.Account)
+}
+
+// vpcFilters returns the server-side filters that scope collection to the configured VPCs.
+func (l *CompliancePlugin) vpcFilters() []types.Filter {
+	if len(l.config.VpcIDs) == 0 {
+		return nil
+	}
+	return []types.Filter{
+		{
+			Name:   aws.String("vpc-id"),
+			Values: l.config.VpcIDs,
+		},
+	}
+}
+
+// tagFilterSet returns one `tag:<key>` filter per configured tag filter. EC2 ANDs separate filters
+// together, so a resource must match every configured tag to be returned.
+func (l *CompliancePlugin) tagFilterSet() []types.Filter {
+	filters := make([]types.Filter, 0, len(l.config.TagFilters))
+	for _, key := range slices.Sorted(maps.Keys(l.config.TagFilters)) {
+		filters = append(filters, types.Filter{
+			Name:   aws.String("tag:" + key),
+			Values: []string{l.config.TagFilters[key]},
+		})
+	}
+	return filters
+}
+
+// evaluatePolicies runs every configured policy against data and returns the combined evidence.
+func (l *CompliancePlugin) evaluatePolicies(ctx context.Context, request *proto.EvalRequest, labels map[string]string, subjects []*proto.Subject, components []*proto.Component, inventory []*proto.InventoryItem, activities []*proto.Activity, data interface{}) ([]*proto.Evidence, error) {
+	var accumulatedErrors error
+	evidences := make([]*proto.Evidence, 0)
+
+	for _, policyPath := range request.GetPolicyPaths() {
+		// Explicitly reset steps to make things readable
+		processor := policyManager.NewPolicyProcessor(
+			l.logger,
+			labels,
+			subjects,
+			components,
+			inventory,
+			actors(),
+			activities,
+		)
+		evidence, err := processor.GenerateResults(ctx, policyPath, data)
+		// label_prefix is applied to the evidence rather than the processor's labels, so the labels
+		// policies return and the agent's _policy label are namespaced too. config-hash is added here
+		// too, as the processor's labels seed the evidence UUID, which must not change with the
+		// configuration.
+		for _, record := range evidence {
+			record.Labels = internal.PrefixKeys(internal.MergeMaps(record.Labels, map[string]string{
+				"config-hash": l.configHash,
+			}), l.config.LabelPrefix)
 		}
-		components := []*proto.Component{
-			{
-				Identifier:  "common-components/amazon-security-group",
-				Type:        "service",
-				Title:       "Amazon Security Groups",
-				Description: "Amazon Security Groups act as virtual firewalls for AWS resources such as EC2 instances and RDS databases. They control inbound and outbound traffic at the instance level using rule-based configurations tied to ports, protocols, and CIDR ranges. Security Groups are stateful and can reference other groups to enforce dynamic trust boundaries within a VPC.",
-				Purpose:     "To enforce network segmentation and access control policies at the resource level, providing a configurable and auditable security boundary for cloud-based assets in support of least privilege and Zero Trust architectures.",
-			},
+		evidences = slices.Concat(evidences, evidence)
+		if err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
 		}
-		inventory := []*proto.InventoryItem{
-			{
-				Identifier: fmt.Sprintf("aws-security-group/%s", aws.ToString(group.GroupId)),
-				Type:       "firewall",
-				Title:      fmt.Sprintf("Amazon Security Group [%s]", aws.ToString(group.GroupId)),
-				Props: []*proto.Property{
-					{
-						Name:  "group-id",
-						Value: aws.ToString(group.GroupId),
-					},
-					{
-						Name:  "group-name",
-						Value: aws.ToString(group.GroupName),
-					},
-					{
-						Name:  "vpc-id",
-						Value: aws.ToString(group.VpcId),
-					},
+	}
+
+	return evidences, accumulatedErrors
+}
+
+// collectionActivities describes the assessment steps taken to collect and evaluate a resource type,
+// e.g. collectionActivities("security group", "DescribeSecurityGroups").
+func collectionActivities(resourceName string, apiOperation string) []*proto.Activity {
+	return []*proto.Activity{
+		{
+			Title:       fmt.Sprintf("Collect %s configuration", resourceName),
+			Description: fmt.Sprintf("Collect the %s configuration from the AWS EC2 API and evaluate it against the configured policies.", resourceName),
+			Steps: []*proto.Step{
+				{
+					Title:       fmt.Sprintf("Call the EC2 %s API", apiOperation),
+					Description: fmt.Sprintf("Page through the EC2 %s API to retrieve every %s in the region.", apiOperation, resourceName),
 				},
-				ImplementedComponents: []*proto.InventoryItemImplementedComponent{
-					{
-						Identifier: "common-components/amazon-security-group",
-					},
+				{
+					Title:       "Evaluate policies",
+					Description: fmt.Sprintf("Evaluate the %s configuration against each configured policy to produce evidence.", resourceName),
 				},
 			},
-		}
-		subjects := []*proto.Subject{
-			{
-				Type:       proto.SubjectType_SUBJECT_TYPE_COMPONENT,
-				Identifier: "common-components/amazon-security-group",
+		},
+	}
+}
+
+// actors returns the origin actors attached to every piece of evidence.
+func actors() []*proto.OriginActor {
+	return []*proto.OriginActor{
+		{
+			Title: "The Continuous Compliance Framework",
+			Type:  "assessment-platform",
+			Links: []*proto.Link{
+				{
+					Href: "https://compliance-framework.github.io/docs/",
+					Rel:  internal.StringAddressed("reference"),
+					Text: internal.StringAddressed("The Continuous Compliance Framework"),
+				},
 			},
-			{
-				Type:       proto.SubjectType_SUBJECT_TYPE_INVENTORY_ITEM,
-				Identifier: fmt.Sprintf("aws-security-group/%s", aws.ToString(group.GroupId)),
+		},
+		{
+			Title: "Continuous Compliance Framework - AWS Networking Security Plugin",
+			Type:  "tool",
+			Links: []*proto.Link{
+				{
+					Href: "https://github.com/compliance-framework/plugin-aws-networking-security",
+					Rel:  internal.StringAddressed("reference"),
+					Text: internal.StringAddressed("The Continuous Compliance Framework's AWS Networking Security Plugin"),
+				},
 			},
-		}
+			Props: versionProperties(),
+		},
+	}
+}
 
-		for _, policyPath := range request.GetPolicyPaths() {
-			// Explicitly reset steps to make things readable
-			processor := policyManager.NewPolicyProcessor(
-				l.logger,
-				internal.MergeMaps(
-					labels,
-					map[string]string{},
-				),
-				subjects,
-				components,
-				inventory,
-				actors,
-				activities,
-			)
-			evidence, err := processor.GenerateResults(ctx, policyPath, group)
-			evidences = slices.Concat(evidences, evidence)
-			if err != nil {
-				accumulatedErrors = errors.Join(accumulatedErrors, err)
-			}
-		}
+// resolveRegions returns the regions to scan. Without a regions config key the region is resolved as
+// the SDK would: from AWS_REGION, then the profile's region, then the instance metadata service when
+// running on EC2.
+func (l *CompliancePlugin) resolveRegions(ctx context.Context) ([]string, error) {
+	if len(l.config.Regions) > 0 {
+		return l.config.Regions, nil
+	}
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		return []string{region}, nil
+	}
 
-		if err = apiHelper.CreateEvidence(ctx, evidences); err != nil {
-			l.logger.Error("Failed to send evidences", "error", err)
-			return &proto.EvalResponse{
-				Status: proto.ExecutionStatus_FAILURE,
-			}, err
-		}
+	opts := []func(*config.LoadOptions) error{
+		config.WithEC2IMDSRegion(),
+	}
+	if l.config.Profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(l.config.Profile))
 	}
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve a default region: %w", err)
+	}
+	if cfg.Region == "" {
+		return nil, errors.New("no region to scan: set the regions config key, AWS_REGION, or a region on the configured profile")
+	}
+	return []string{cfg.Region}, nil
+}
 
-	return &proto.EvalResponse{
-		Status: evalStatus,
-	}, accumulatedErrors
+// dryRunApiHelper logs the evidence that would have been sent instead of sending it, so policies can be
+// tuned locally without polluting the evidence store.
+type dryRunApiHelper struct {
+	logger hclog.Logger
 }
 
-func getSecurityGroups(ctx context.Context, client *ec2.Client) iter.Seq2[types.SecurityGroup, error] {
-	return func(yield func(types.SecurityGroup, error) bool) {
-		result, err := client.DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{})
-		if err != nil {
-			yield(types.SecurityGroup{}, err)
-			return
-		}
+func (d *dryRunApiHelper) CreateEvidence(ctx context.Context, evidence []*proto.Evidence) error {
+	for _, item := range evidence {
+		d.logger.Debug("Dry run: skipping evidence submission", "evidence", internal.RedactAccountIDs(protojson.Format(item)))
+	}
+	return nil
+}
 
-		for _, group := range result.SecurityGroups {
-			if !yield(group, nil) {
-				return
+// loadAWSConfig loads the SDK configuration for a region, as returned by resolveRegions.
+func (l *CompliancePlugin) loadAWSConfig(ctx context.Context, region string) (aws.Config, error) {
+	opts := []func(*config.LoadOptions) error{
+		config.WithRegion(region),
+		// The standard retryer backs off exponentially with jitter and treats throttling errors such
+		// as RequestLimitExceeded as retryable.
+		config.WithRetryer(func() aws.Retryer {
+			return retry.NewStandard(func(o *retry.StandardOptions) {
+				o.MaxAttempts = l.config.MaxRetries + 1
+			})
+		}),
+	}
+	if l.config.Profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(l.config.Profile))
+	}
+	if l.config.AccessKeyID != "" {
+		opts = append(opts, config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(l.config.AccessKeyID, l.config.SecretAccessKey, l.config.SessionToken)))
+	}
+	if l.config.EndpointURL != "" {
+		// Applies to every client built from this config (EC2 and STS), which is what emulators
+		// such as LocalStack expect.
+		opts = append(opts, config.WithBaseEndpoint(l.config.EndpointURL))
+	}
+	if l.config.AWSSDKDebug {
+		// Responses carry the x-amzn-RequestId header, which AWS support needs to trace a call.
+		opts = append(opts,
+			config.WithClientLogMode(aws.LogRequest|aws.LogResponse|aws.LogRetries),
+			config.WithLogger(sdkLogger{logger: l.logger.Named("aws-sdk")}),
+		)
+	}
+	requestTimeout := l.config.AWSRequestTimeout
+	if requestTimeout <= 0 {
+		requestTimeout = internal.DefaultAWSRequestTimeout
+	}
+	httpClient := awshttp.NewBuildableClient().WithTimeout(requestTimeout)
+	if l.config.DisableSSL {
+		httpClient = httpClient.WithTransportOptions(func(t *http.Transport) {
+			if t.TLSClientConfig == nil {
+				t.TLSClientConfig = &tls.Config{}
 			}
-		}
+			t.TLSClientConfig.InsecureSkipVerify = true
+		})
 	}
+	opts = append(opts, config.WithHTTPClient(httpClient))
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return cfg, err
+	}
+	// The limiter is only wrapped around the HTTP client once the config is loaded, as loading applies
+	// AWS_CA_BUNDLE to the client, which it can only do to the SDK's own client type.
+	if l.rateLimiter != nil {
+		cfg.HTTPClient = &rateLimitedClient{client: cfg.HTTPClient, limiter: l.rateLimiter}
+	}
+
+	for i, roleArn := range l.config.AssumeRoleChain {
+		// Each hop's STS client is built from the config holding the previous hop's credentials.
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), roleArn, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = l.config.AssumeRoleSessionName
+			if l.config.AssumeRoleDuration > 0 {
+				o.Duration = l.config.AssumeRoleDuration
+			}
+			if l.config.ExternalID != "" && i == len(l.config.AssumeRoleChain)-1 {
+				o.ExternalID = aws.String(l.config.ExternalID)
+			}
+		})
+		cfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+
+	return cfg, nil
 }
 
 func main() {
+	// --config-schema prints the configuration schema for config authoring tools instead of serving.
+	if slices.Contains(os.Args[1:], "--config-schema") {
+		schema, err := json.MarshalIndent(configSchema(), "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(string(schema))
+		return
+	}
+
+	// Until the plugin is configured with a log_level, the default info level is used.
 	logger := hclog.New(&hclog.LoggerOptions{
-		Level:      hclog.Debug,
+		Level:      hclog.Info,
 		JSONFormat: true,
 	})
 
-	compliancePluginObj := &CompliancePlugin{
-		logger: logger,
-	}
+	compliancePluginObj := NewCompliancePlugin(logger)
 	// pluginMap is the map of plugins we can dispense.
-	logger.Debug("Initiating AWS network security plugin")
+	buildVersion, buildCommit := pluginVersion()
+	logger.Info("Initiating AWS network security plugin", "version", buildVersion, "commit", buildCommit)
 
 	goplugin.Serve(&goplugin.ServeConfig{
 		HandshakeConfig: runner.HandshakeConfig,
@@ -0,0 +1,568 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal/awsmock"
+	"github.com/hashicorp/go-hclog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordingApiHelper records every batch of evidence sent to the agent.
+type recordingApiHelper struct {
+	mu      sync.Mutex
+	batches [][]*proto.Evidence
+}
+
+func (r *recordingApiHelper) CreateEvidence(_ context.Context, evidence []*proto.Evidence) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, evidence)
+	return nil
+}
+
+// evidence returns every evidence record sent, in order.
+func (r *recordingApiHelper) evidence() []*proto.Evidence {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var evidence []*proto.Evidence
+	for _, batch := range r.batches {
+		evidence = append(evidence, batch...)
+	}
+	return evidence
+}
+
+// evidenceOfType returns the evidence sent whose type label is resourceType.
+func (r *recordingApiHelper) evidenceOfType(resourceType string) []*proto.Evidence {
+	var evidence []*proto.Evidence
+	for _, record := range r.evidence() {
+		if record.Labels["type"] == resourceType {
+			evidence = append(evidence, record)
+		}
+	}
+	return evidence
+}
+
+// newTestPlugin returns a plugin configured with config, on top of static credentials and a single
+// region, whose AWS clients are the fakes given.
+func newTestPlugin(t *testing.T, config map[string]string, client *awsmock.EC2) *CompliancePlugin {
+	t.Helper()
+	plugin := NewCompliancePlugin(hclog.NewNullLogger())
+	plugin.newNetworkingClient = func(aws.Config) NetworkingAPI { return client }
+	plugin.newIdentityClient = func(aws.Config) IdentityAPI {
+		return &awsmock.STS{Account: "123456789012", Arn: "arn:aws:iam::123456789012:role/scanner"}
+	}
+	plugin.newTaggingClient = func(aws.Config) TaggingAPI { return &awsmock.Tagging{} }
+	plugin.newSharingClient = func(aws.Config) SharingAPI { return &awsmock.RAM{} }
+
+	raw := map[string]string{
+		"regions":               "us-east-1",
+		"aws_access_key_id":     "AKIDEXAMPLE",
+		"aws_secret_access_key": "secret",
+	}
+	maps.Copy(raw, config)
+	if _, err := plugin.Configure(&proto.ConfigureRequest{Config: raw}); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	return plugin
+}
+
+// testEvalRequest evaluates every resource against the pass-through policy in testdata/policy.
+var testEvalRequest = &proto.EvalRequest{PolicyPaths: []string{"testdata/policy"}}
+
+func securityGroup(id string, vpcID string) types.SecurityGroup {
+	return types.SecurityGroup{
+		GroupId:   aws.String(id),
+		GroupName: aws.String(id),
+		VpcId:     aws.String(vpcID),
+		OwnerId:   aws.String("123456789012"),
+	}
+}
+
+func TestEvalSendsEvidenceInBatches(t *testing.T) {
+	client := &awsmock.EC2{
+		Vpcs: [][]types.Vpc{{{VpcId: aws.String("vpc-1"), CidrBlock: aws.String("10.0.0.0/16")}}},
+		SecurityGroups: [][]types.SecurityGroup{
+			{securityGroup("sg-1", "vpc-1"), securityGroup("sg-2", "vpc-1")},
+			{securityGroup("sg-3", "vpc-1")},
+		},
+	}
+	plugin := newTestPlugin(t, map[string]string{
+		"resources":           "security-groups",
+		"evidence_batch_size": "2",
+	}, client)
+	apiHelper := &recordingApiHelper{}
+
+	response, err := plugin.Eval(testEvalRequest, apiHelper)
+	if err != nil {
+		t.Fatalf("Eval: %v", err)
+	}
+	if response.GetStatus() != proto.ExecutionStatus_SUCCESS {
+		t.Errorf("status = %s, want SUCCESS", response.GetStatus())
+	}
+
+	for i, batch := range apiHelper.batches {
+		if len(batch) == 0 || len(batch) > 2 {
+			t.Errorf("batch %d has %d records, want 1 to evidence_batch_size", i, len(batch))
+		}
+	}
+	evidence := apiHelper.evidence()
+	uuids := map[string]bool{}
+	for _, record := range evidence {
+		if uuids[record.GetUUID()] {
+			t.Errorf("evidence %q sent twice", record.GetUUID())
+		}
+		uuids[record.GetUUID()] = true
+		labels := []string{"config-hash", "provider", "region", "account-id"}
+		if record.Labels["type"] == "run-summary" {
+			labels = labels[:2]
+		}
+		for _, label := range labels {
+			if record.Labels[label] == "" {
+				t.Errorf("evidence %q has no %s label: %v", record.GetTitle(), label, record.Labels)
+			}
+		}
+	}
+
+	groups := apiHelper.evidenceOfType("security-group")
+	if len(groups) != 3 {
+		t.Fatalf("got %d security group evidence records, want 3", len(groups))
+	}
+	for _, record := range groups {
+		if record.Labels["_policy"] != "compliance_framework.test_policy" {
+			t.Errorf("security group evidence has _policy %q, want compliance_framework.test_policy", record.Labels["_policy"])
+		}
+		if len(record.GetInventoryItems()) != 1 {
+			t.Errorf("security group evidence has %d inventory items, want 1", len(record.GetInventoryItems()))
+		}
+	}
+	if got := len(apiHelper.evidenceOfType("region-scan-status")); got != 1 {
+		t.Errorf("got %d scan status records, want 1", got)
+	}
+	if last := evidence[len(evidence)-1]; last.Labels["type"] != "run-summary" {
+		t.Errorf("last evidence has type %q, want run-summary", last.Labels["type"])
+	}
+}
+
+func TestEvalReportsErrorOnLaterPage(t *testing.T) {
+	client := &awsmock.EC2{
+		Vpcs: [][]types.Vpc{{{VpcId: aws.String("vpc-1")}}},
+		SecurityGroups: [][]types.SecurityGroup{
+			{securityGroup("sg-1", "vpc-1")},
+			{securityGroup("sg-2", "vpc-1")},
+		},
+		Subnets: [][]types.Subnet{{{SubnetId: aws.String("subnet-1"), VpcId: aws.String("vpc-1")}}},
+		Errors:  map[string]error{"DescribeSecurityGroups/1": errors.New("page unavailable")},
+	}
+	plugin := newTestPlugin(t, map[string]string{"resources": "security-groups,subnets"}, client)
+	apiHelper := &recordingApiHelper{}
+
+	response, err := plugin.Eval(testEvalRequest, apiHelper)
+	if err == nil {
+		t.Fatal("Eval succeeded, want the later page's error")
+	}
+	// The security groups of the first page were still evaluated, and subnets collected, so the run is a
+	// partial success.
+	if response.GetStatus() != proto.ExecutionStatus_SUCCESS {
+		t.Errorf("status = %s, want SUCCESS", response.GetStatus())
+	}
+	if got := len(apiHelper.evidenceOfType("security-group")); got != 1 {
+		t.Errorf("got %d security group evidence records, want 1", got)
+	}
+}
+
+// throttlingServer serves DescribeSecurityGroups, failing the first throttles requests with
+// RequestLimitExceeded. It returns the server and a pointer to the number of requests received.
+func throttlingServer(t *testing.T, throttles int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(requests.Add(1)) <= throttles {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `<Response><Errors><Error><Code>RequestLimitExceeded</Code><Message>Request limit exceeded.</Message></Error></Errors><RequestID>1</RequestID></Response>`)
+			return
+		}
+		fmt.Fprint(w, `<DescribeSecurityGroupsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/"><requestId>2</requestId><securityGroupInfo><item><groupId>sg-1</groupId><groupName>web</groupName></item></securityGroupInfo></DescribeSecurityGroupsResponse>`)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestLoadAWSConfigRetriesThrottling(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxRetries   string
+		wantGroups   []string
+		wantErr      bool
+		wantRequests int32
+	}{
+		{name: "throttle then success", maxRetries: "2", wantGroups: []string{"sg-1"}, wantRequests: 2},
+		{name: "retries disabled", maxRetries: "0", wantErr: true, wantRequests: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, requests := throttlingServer(t, 1)
+			plugin := newTestPlugin(t, map[string]string{
+				"endpoint_url": server.URL,
+				"max_retries":  test.maxRetries,
+			}, &awsmock.EC2{})
+
+			cfg, err := plugin.loadAWSConfig(context.Background(), "us-east-1")
+			if err != nil {
+				t.Fatalf("loadAWSConfig: %v", err)
+			}
+			// The configured retryer is kept, only its backoff is shortened to keep the test fast.
+			client := ec2.NewFromConfig(cfg, func(o *ec2.Options) {
+				o.Retryer = retry.AddWithMaxBackoffDelay(o.Retryer, time.Millisecond)
+			})
+
+			var groups []string
+			var errs []error
+			for group, err := range getSecurityGroups(context.Background(), client, &ec2.DescribeSecurityGroupsInput{}) {
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				groups = append(groups, aws.ToString(group.GroupId))
+			}
+			if !slices.Equal(groups, test.wantGroups) {
+				t.Errorf("groups = %v, want %v", groups, test.wantGroups)
+			}
+			if got := len(errs) > 0; got != test.wantErr {
+				t.Errorf("errors = %v, want error: %t", errs, test.wantErr)
+			}
+			if got := requests.Load(); got != test.wantRequests {
+				t.Errorf("%d requests made, want %d", got, test.wantRequests)
+			}
+		})
+	}
+}
+
+// inventoryIdentifiers returns the identifiers of the inventory items of the evidence of resourceType.
+func (r *recordingApiHelper) inventoryIdentifiers(resourceType string) []string {
+	var identifiers []string
+	for _, record := range r.evidenceOfType(resourceType) {
+		for _, item := range record.GetInventoryItems() {
+			identifiers = append(identifiers, item.GetIdentifier())
+		}
+	}
+	slices.Sort(identifiers)
+	return identifiers
+}
+
+func TestEvalSkipsDefaultVpc(t *testing.T) {
+	newClient := func() *awsmock.EC2 {
+		return &awsmock.EC2{
+			Vpcs: [][]types.Vpc{{
+				{VpcId: aws.String("vpc-default"), IsDefault: aws.Bool(true)},
+				{VpcId: aws.String("vpc-custom"), IsDefault: aws.Bool(false)},
+			}},
+			SecurityGroups: [][]types.SecurityGroup{{securityGroup("sg-default", "vpc-default"), securityGroup("sg-custom", "vpc-custom")}},
+			Subnets: [][]types.Subnet{{
+				{SubnetId: aws.String("subnet-default"), VpcId: aws.String("vpc-default")},
+				{SubnetId: aws.String("subnet-custom"), VpcId: aws.String("vpc-custom")},
+			}},
+		}
+	}
+	tests := []struct {
+		name        string
+		config      map[string]string
+		wantGroups  []string
+		wantSubnets []string
+	}{
+		{
+			name:        "default VPC scanned",
+			config:      map[string]string{},
+			wantGroups:  []string{"aws-security-group/sg-custom", "aws-security-group/sg-default"},
+			wantSubnets: []string{"aws-subnet/subnet-custom", "aws-subnet/subnet-default"},
+		},
+		{
+			name:        "default VPC skipped",
+			config:      map[string]string{"skip_default_vpc": "true"},
+			wantGroups:  []string{"aws-security-group/sg-custom"},
+			wantSubnets: []string{"aws-subnet/subnet-custom"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.config["resources"] = "security-groups,subnets"
+			plugin := newTestPlugin(t, test.config, newClient())
+			apiHelper := &recordingApiHelper{}
+
+			if _, err := plugin.Eval(testEvalRequest, apiHelper); err != nil {
+				t.Fatalf("Eval: %v", err)
+			}
+			if got := apiHelper.inventoryIdentifiers("security-group"); !slices.Equal(got, test.wantGroups) {
+				t.Errorf("security groups = %v, want %v", got, test.wantGroups)
+			}
+			if got := apiHelper.inventoryIdentifiers("subnet"); !slices.Equal(got, test.wantSubnets) {
+				t.Errorf("subnets = %v, want %v", got, test.wantSubnets)
+			}
+		})
+	}
+
+	// vpc_ids is applied by EC2 filters, so the fake only returns the listed default VPC's resources.
+	t.Run("default VPC listed in vpc_ids", func(t *testing.T) {
+		client := &awsmock.EC2{
+			Vpcs:           [][]types.Vpc{{{VpcId: aws.String("vpc-default"), IsDefault: aws.Bool(true)}}},
+			SecurityGroups: [][]types.SecurityGroup{{securityGroup("sg-default", "vpc-default")}},
+		}
+		plugin := newTestPlugin(t, map[string]string{
+			"resources":        "security-groups",
+			"skip_default_vpc": "true",
+			"vpc_ids":          "vpc-default",
+		}, client)
+		apiHelper := &recordingApiHelper{}
+
+		if _, err := plugin.Eval(testEvalRequest, apiHelper); err != nil {
+			t.Fatalf("Eval: %v", err)
+		}
+		if got := apiHelper.inventoryIdentifiers("security-group"); !slices.Equal(got, []string{"aws-security-group/sg-default"}) {
+			t.Errorf("security groups = %v, want [aws-security-group/sg-default]", got)
+		}
+	})
+}
+
+func TestEvalExitsEarlyOnTimeout(t *testing.T) {
+	var mu sync.Mutex
+	var operations []string
+	client := &awsmock.EC2{
+		Vpcs:           [][]types.Vpc{{{VpcId: aws.String("vpc-1")}}},
+		SecurityGroups: [][]types.SecurityGroup{{securityGroup("sg-1", "vpc-1")}},
+		// Subnets are described until the evaluation times out.
+		Hook: func(ctx context.Context, operation string) error {
+			mu.Lock()
+			operations = append(operations, operation)
+			mu.Unlock()
+			if operation == "DescribeSubnets" {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return nil
+		},
+	}
+	plugin := newTestPlugin(t, map[string]string{
+		"resources":       "security-groups,subnets,route-tables",
+		"max_concurrency": "1",
+		"eval_timeout":    "1",
+	}, client)
+	apiHelper := &recordingApiHelper{}
+
+	start := time.Now()
+	response, err := plugin.Eval(testEvalRequest, apiHelper)
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Eval took %s after a timeout of 1s", elapsed)
+	}
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Eval error = %v, want a timeout", err)
+	}
+	if response.GetStatus() != proto.ExecutionStatus_FAILURE {
+		t.Errorf("status = %s, want FAILURE", response.GetStatus())
+	}
+
+	// Evidence collected before the timeout is still sent, along with the run summary.
+	if got := len(apiHelper.evidenceOfType("security-group")); got != 1 {
+		t.Errorf("got %d security group evidence records, want 1", got)
+	}
+	if got := len(apiHelper.evidenceOfType("run-summary")); got != 1 {
+		t.Errorf("got %d run summaries, want 1", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if slices.Contains(operations, "DescribeRouteTables") {
+		t.Errorf("route tables were described after the timeout: %v", operations)
+	}
+}
+
+func TestEvalRegionCancelled(t *testing.T) {
+	var mu sync.Mutex
+	var operations []string
+	client := &awsmock.EC2{
+		Hook: func(_ context.Context, operation string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			operations = append(operations, operation)
+			return nil
+		},
+	}
+	plugin := newTestPlugin(t, map[string]string{}, client)
+	apiHelper := &recordingApiHelper{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := plugin.evalRegion(ctx, "us-east-1", testEvalRequest, apiHelper, newRunSummary(0), make(chan struct{}, 1))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("evalRegion error = %v, want context.Canceled", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(operations) > 0 {
+		t.Errorf("AWS was called after cancellation: %v", operations)
+	}
+	if got := len(apiHelper.evidenceOfType("region-scan-status")); got != 1 {
+		t.Errorf("got %d region scan statuses, want 1", got)
+	}
+}
+
+func TestEvalRegionCancelledWaitingForWorker(t *testing.T) {
+	client := &awsmock.EC2{
+		Hook: func(_ context.Context, operation string) error {
+			t.Errorf("AWS was called while waiting for a worker: %s", operation)
+			return nil
+		},
+	}
+	plugin := newTestPlugin(t, map[string]string{}, client)
+	apiHelper := &recordingApiHelper{}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// The only worker is held elsewhere for the whole evaluation.
+	workers := make(chan struct{}, 1)
+	workers <- struct{}{}
+	err := plugin.evalRegion(ctx, "us-east-1", testEvalRequest, apiHelper, newRunSummary(0), workers)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("evalRegion error = %v, want context.DeadlineExceeded", err)
+	}
+	statuses := apiHelper.evidenceOfType("region-scan-status")
+	if len(statuses) != 1 {
+		t.Fatalf("got %d region scan statuses, want 1", len(statuses))
+	}
+}
+
+func TestEvalLinksPrefixListsWithoutTheirPass(t *testing.T) {
+	group := securityGroup("sg-1", "vpc-1")
+	group.IpPermissions = []types.IpPermission{{
+		IpProtocol:    aws.String("tcp"),
+		FromPort:      aws.Int32(443),
+		ToPort:        aws.Int32(443),
+		PrefixListIds: []types.PrefixListId{{PrefixListId: aws.String("pl-1")}},
+	}}
+	client := &awsmock.EC2{
+		Vpcs:           [][]types.Vpc{{{VpcId: aws.String("vpc-1")}}},
+		SecurityGroups: [][]types.SecurityGroup{{group}},
+		PrefixLists:    [][]types.ManagedPrefixList{{{PrefixListId: aws.String("pl-1")}}},
+		PrefixListEntries: map[string][][]types.PrefixListEntry{
+			"pl-1": {{{Cidr: aws.String("198.51.100.0/24")}, {Cidr: aws.String("203.0.113.0/24")}}},
+		},
+	}
+	plugin := newTestPlugin(t, map[string]string{"resources": "security-groups"}, client)
+	apiHelper := &recordingApiHelper{}
+	if _, err := plugin.Eval(testEvalRequest, apiHelper); err != nil {
+		t.Fatalf("Eval: %v", err)
+	}
+
+	if got := len(apiHelper.evidenceOfType("managed-prefix-list")); got != 0 {
+		t.Errorf("got %d managed prefix list evidence records, want none", got)
+	}
+	groups := apiHelper.evidenceOfType("security-group")
+	if len(groups) != 1 {
+		t.Fatalf("got %d security group evidence records, want 1", len(groups))
+	}
+	var cidrs []string
+	for _, item := range groups[0].GetInventoryItems() {
+		for _, prop := range item.GetProps() {
+			if strings.HasSuffix(prop.GetName(), "/prefix-list-cidrs") {
+				cidrs = append(cidrs, prop.GetValue())
+			}
+		}
+	}
+	if want := []string{"198.51.100.0/24,203.0.113.0/24"}; !slices.Equal(cidrs, want) {
+		t.Errorf("prefix-list-cidrs = %q, want %q", cidrs, want)
+	}
+}
+
+// TestEvalBoundsConcurrentPasses is meant to be run with -race. Every AWS call of the run, in any
+// region, is counted while in flight.
+func TestEvalBoundsConcurrentPasses(t *testing.T) {
+	const maxConcurrency = 2
+	var inFlight, peak atomic.Int32
+	client := &awsmock.EC2{
+		Vpcs:           [][]types.Vpc{{{VpcId: aws.String("vpc-1")}}},
+		SecurityGroups: [][]types.SecurityGroup{{securityGroup("sg-1", "vpc-1")}, {securityGroup("sg-2", "vpc-1")}},
+		Subnets:        [][]types.Subnet{{{SubnetId: aws.String("subnet-1"), VpcId: aws.String("vpc-1")}}},
+		Hook: func(context.Context, string) error {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				previous := peak.Load()
+				if current <= previous || peak.CompareAndSwap(previous, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		},
+	}
+	plugin := newTestPlugin(t, map[string]string{
+		"regions":         "us-east-1,us-west-2,eu-west-1",
+		"max_concurrency": strconv.Itoa(maxConcurrency),
+	}, client)
+	apiHelper := &recordingApiHelper{}
+
+	if _, err := plugin.Eval(testEvalRequest, apiHelper); err != nil {
+		t.Fatalf("Eval: %v", err)
+	}
+	if got := peak.Load(); got != maxConcurrency {
+		t.Errorf("at most %d AWS calls were in flight, want max_concurrency, %d", got, maxConcurrency)
+	}
+	if got := len(apiHelper.evidenceOfType("security-group")); got != 6 {
+		t.Errorf("got %d security group evidence records, want 2 in each of 3 regions", got)
+	}
+	if got := len(apiHelper.evidenceOfType("region-scan-status")); got != 3 {
+		t.Errorf("got %d region scan statuses, want 3", got)
+	}
+}
+
+func TestEvidenceUUIDsSurviveConfigChanges(t *testing.T) {
+	uuids := func(config map[string]string) (map[string]string, string) {
+		client := &awsmock.EC2{
+			Vpcs:           [][]types.Vpc{{{VpcId: aws.String("vpc-1")}}},
+			SecurityGroups: [][]types.SecurityGroup{{securityGroup("sg-1", "vpc-1")}},
+		}
+		config["resources"] = "security-groups"
+		plugin := newTestPlugin(t, config, client)
+		apiHelper := &recordingApiHelper{}
+		if _, err := plugin.Eval(testEvalRequest, apiHelper); err != nil {
+			t.Fatalf("Eval: %v", err)
+		}
+		result := map[string]string{}
+		for _, record := range apiHelper.evidence() {
+			if record.Labels["config-hash"] != plugin.configHash {
+				t.Errorf("evidence %q has config-hash %q, want %q", record.GetTitle(), record.Labels["config-hash"], plugin.configHash)
+			}
+			result[record.Labels["type"]] = record.GetUUID()
+		}
+		return result, plugin.configHash
+	}
+
+	before, beforeHash := uuids(map[string]string{})
+	after, afterHash := uuids(map[string]string{"approved_cidrs": "203.0.113.0/24"})
+	if beforeHash == afterHash {
+		t.Fatal("config-hash did not change with approved_cidrs")
+	}
+	for _, resourceType := range []string{"security-group", "region-scan-status"} {
+		if before[resourceType] == "" || before[resourceType] != after[resourceType] {
+			t.Errorf("%s evidence UUID changed with the configuration: %q, %q", resourceType, before[resourceType], after[resourceType])
+		}
+	}
+
+	// Operational keys do not change the hash at all.
+	if _, hash := uuids(map[string]string{"log_level": "debug", "max_concurrency": "1", "dry_run": "false"}); hash != beforeHash {
+		t.Errorf("config-hash changed with operational keys")
+	}
+}
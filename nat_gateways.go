@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"iter"
+	"slices"
+)
+
+var natGatewayComponent = &proto.Component{
+	Identifier:  "common-components/amazon-nat-gateway",
+	Type:        "service",
+	Title:       "Amazon VPC NAT Gateways",
+	Description: "Amazon VPC NAT gateways allow resources in private subnets to initiate outbound connections to other networks. Public NAT gateways reach the internet through an internet gateway, while private NAT gateways only translate traffic towards other VPCs or on-premises networks.",
+	Purpose:     "To provide controlled, outbound-only connectivity for private resources, keeping them unreachable from the internet while making their egress paths auditable.",
+}
+
+func (l *CompliancePlugin) evalNatGateways(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	input := &ec2.DescribeNatGatewaysInput{
+		Filter: l.vpcFilters(),
+	}
+
+	found := 0
+	for gateway, err := range getNatGateways(ctx, scan.client, input) {
+		if err != nil {
+			l.logger.Error("unable to get NAT gateway", "region", scan.region, "error", err)
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+			continue
+		}
+		if scan.excludesVpc(aws.ToString(gateway.VpcId)) {
+			continue
+		}
+		if l.excludesState(scan, "nat-gateways", "nat-gateway", string(gateway.State)) {
+			continue
+		}
+		found++
+
+		gatewayID := aws.ToString(gateway.NatGatewayId)
+
+		labels := internal.MergeMaps(scan.labels, l.tagLabels(gateway.Tags), map[string]string{
+			"type":              "nat-gateway",
+			"nat-gateway-id":    gatewayID,
+			"connectivity-type": string(gateway.ConnectivityType),
+			"subnet-id":         aws.ToString(gateway.SubnetId),
+			"_vpc-id":           aws.ToString(gateway.VpcId),
+		})
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-nat-gateway/%s", gatewayID),
+			Type:       "network",
+			Title:      fmt.Sprintf("Amazon NAT Gateway [%s]", gatewayID),
+			Props: slices.Concat([]*proto.Property{
+				{
+					Name:  "nat-gateway-id",
+					Value: gatewayID,
+				},
+				{
+					Name:  "vpc-id",
+					Value: aws.ToString(gateway.VpcId),
+				},
+				{
+					Name:  "subnet-id",
+					Value: aws.ToString(gateway.SubnetId),
+				},
+				{
+					Name:  "state",
+					Value: string(gateway.State),
+				},
+				{
+					Name:  "connectivity-type",
+					Value: string(gateway.ConnectivityType),
+				},
+			}, tagProperties(gateway.Tags)),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, natGatewayComponent, inventory, collectionActivities("NAT gateway", "DescribeNatGateways"), gateway); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	if found == 0 && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "nat-gateway", "NAT gateways", collectionActivities("NAT gateway", "DescribeNatGateways"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+func getNatGateways(ctx context.Context, client NetworkingAPI, input *ec2.DescribeNatGatewaysInput) iter.Seq2[types.NatGateway, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeNatGatewaysOutput, *ec2.Options] {
+		return ec2.NewDescribeNatGatewaysPaginator(client, input)
+	}, func(page *ec2.DescribeNatGatewaysOutput) []types.NatGateway {
+		return page.NatGateways
+	})
+}
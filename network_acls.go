@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// naclDefaultRuleNumber is the number of the deny-all entry AWS adds to every network ACL.
+const naclDefaultRuleNumber = 32767
+
+var networkACLComponent = &proto.Component{
+	Identifier:  "common-components/amazon-network-acl",
+	Type:        "service",
+	Title:       "Amazon VPC Network ACLs",
+	Description: "Amazon VPC Network Access Control Lists are stateless firewalls applied at the subnet level. They evaluate numbered allow and deny rules in order against inbound and outbound traffic crossing a subnet boundary, based on protocol, port range, and CIDR.",
+	Purpose:     "To provide a coarse-grained, subnet-level network boundary that complements security groups, enforcing segmentation and defence in depth for resources within a VPC.",
+}
+
+func (l *CompliancePlugin) evalNetworkACLs(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	input := &ec2.DescribeNetworkAclsInput{
+		Filters: l.vpcFilters(),
+	}
+
+	found := 0
+	for acl, err := range getNetworkACLs(ctx, scan.client, input) {
+		if err != nil {
+			l.logger.Error("unable to get network ACL", "region", scan.region, "error", err)
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+			continue
+		}
+		if scan.excludesVpc(aws.ToString(acl.VpcId)) {
+			continue
+		}
+		found++
+
+		labels := internal.MergeMaps(scan.labels, map[string]string{
+			"type":           "network-acl",
+			"network-acl-id": aws.ToString(acl.NetworkAclId),
+			"_vpc-id":        aws.ToString(acl.VpcId),
+		})
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-network-acl/%s", aws.ToString(acl.NetworkAclId)),
+			Type:       "firewall",
+			Title:      fmt.Sprintf("Amazon Network ACL [%s]", aws.ToString(acl.NetworkAclId)),
+			Props: slices.Concat([]*proto.Property{
+				{
+					Name:  "network-acl-id",
+					Value: aws.ToString(acl.NetworkAclId),
+				},
+				{
+					Name:  "vpc-id",
+					Value: aws.ToString(acl.VpcId),
+				},
+			}, naclProperties(acl, l.config.SensitivePorts)),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, networkACLComponent, inventory, collectionActivities("network ACL", "DescribeNetworkAcls"), acl); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	if found == 0 && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "network-acl", "network ACLs", collectionActivities("network ACL", "DescribeNetworkAcls"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+// naclEntries returns the ingress or egress entries of acl ordered by rule number, the order in which
+// AWS evaluates them.
+func naclEntries(acl types.NetworkAcl, egress bool) []types.NetworkAclEntry {
+	entries := make([]types.NetworkAclEntry, 0)
+	for _, entry := range acl.Entries {
+		if aws.ToBool(entry.Egress) == egress {
+			entries = append(entries, entry)
+		}
+	}
+	slices.SortFunc(entries, func(a, b types.NetworkAclEntry) int {
+		return int(aws.ToInt32(a.RuleNumber)) - int(aws.ToInt32(b.RuleNumber))
+	})
+	return entries
+}
+
+// isNaclInternetEntry reports whether the entry applies to all of 0.0.0.0/0 or ::/0.
+func isNaclInternetEntry(entry types.NetworkAclEntry) bool {
+	return aws.ToString(entry.CidrBlock) == internetCidrIPv4 || aws.ToString(entry.Ipv6CidrBlock) == internetCidrIPv6
+}
+
+// isNaclAllTrafficEntry reports whether the entry matches every protocol from or to the internet.
+func isNaclAllTrafficEntry(entry types.NetworkAclEntry) bool {
+	return aws.ToString(entry.Protocol) == "-1" && isNaclInternetEntry(entry)
+}
+
+// naclEntryCoversPort reports whether a TCP, UDP or all-protocol entry applies to port.
+func naclEntryCoversPort(entry types.NetworkAclEntry, port int32) bool {
+	switch aws.ToString(entry.Protocol) {
+	case "-1":
+		return true
+	case "6", "17":
+		return entry.PortRange != nil && internal.PortInRange(aws.ToInt32(entry.PortRange.From), aws.ToInt32(entry.PortRange.To), port)
+	}
+	return false
+}
+
+// naclAllowsFromInternet reports whether the internet can reach port over IPv4 or IPv6. AWS evaluates
+// each address family on its own, so an IPv4 deny does not stop IPv6 traffic; within a family the first
+// of the ordered entries applying to the whole internet and port decides. Entries for narrower CIDRs do
+// not decide for the internet as a whole and are passed over.
+func naclAllowsFromInternet(entries []types.NetworkAclEntry, port int32) bool {
+	return naclFamilyAllowsFromInternet(entries, port, func(entry types.NetworkAclEntry) bool {
+		return aws.ToString(entry.CidrBlock) == internetCidrIPv4
+	}) || naclFamilyAllowsFromInternet(entries, port, func(entry types.NetworkAclEntry) bool {
+		return aws.ToString(entry.Ipv6CidrBlock) == internetCidrIPv6
+	})
+}
+
+// naclFamilyAllowsFromInternet reports whether the first ordered entry matched by isInternet that
+// covers port allows it.
+func naclFamilyAllowsFromInternet(entries []types.NetworkAclEntry, port int32, isInternet func(types.NetworkAclEntry) bool) bool {
+	for _, entry := range entries {
+		if isInternet(entry) && naclEntryCoversPort(entry, port) {
+			return entry.RuleAction == types.RuleActionAllow
+		}
+	}
+	return false
+}
+
+// naclProperties lists the ingress and egress entries of acl in rule number order and derives the
+// exposure signals policies assert on: broad allows, explicit deny-all entries and sensitive ports
+// allowed from the internet.
+func naclProperties(acl types.NetworkAcl, sensitivePorts []int32) []*proto.Property {
+	props := make([]*proto.Property, 0)
+	ingress := naclEntries(acl, false)
+	egress := naclEntries(acl, true)
+	for _, direction := range []struct {
+		name    string
+		entries []types.NetworkAclEntry
+	}{
+		{name: "inbound", entries: ingress},
+		{name: "outbound", entries: egress},
+	} {
+		props = append(props,
+			&proto.Property{
+				Name: "has-allow-all-" + direction.name,
+				Value: strconv.FormatBool(slices.ContainsFunc(direction.entries, func(entry types.NetworkAclEntry) bool {
+					return isNaclAllTrafficEntry(entry) && entry.RuleAction == types.RuleActionAllow
+				})),
+			},
+			// AWS appends an implicit deny-all numbered 32767 to every ACL; only entries before it count
+			// as explicit.
+			&proto.Property{
+				Name: "has-explicit-deny-all-" + direction.name,
+				Value: strconv.FormatBool(slices.ContainsFunc(direction.entries, func(entry types.NetworkAclEntry) bool {
+					return isNaclAllTrafficEntry(entry) && entry.RuleAction == types.RuleActionDeny && aws.ToInt32(entry.RuleNumber) < naclDefaultRuleNumber
+				})),
+			},
+		)
+		for i, entry := range direction.entries {
+			props = append(props, naclEntryProperties(fmt.Sprintf("%s-entry/%d", direction.name, i), entry)...)
+		}
+	}
+
+	allowedPorts := make([]string, 0)
+	for _, port := range sensitivePorts {
+		if naclAllowsFromInternet(ingress, port) {
+			allowedPorts = append(allowedPorts, strconv.Itoa(int(port)))
+		}
+	}
+	if len(allowedPorts) > 0 {
+		props = append(props, &proto.Property{
+			Name:  "open-to-internet-sensitive-ports",
+			Value: strings.Join(allowedPorts, ","),
+		})
+	}
+
+	return props
+}
+
+// naclEntryProperties renders an entry as properties named `<prefix>/<field>`.
+func naclEntryProperties(prefix string, entry types.NetworkAclEntry) []*proto.Property {
+	props := []*proto.Property{
+		{
+			Name:  prefix + "/rule-number",
+			Value: strconv.Itoa(int(aws.ToInt32(entry.RuleNumber))),
+		},
+		{
+			Name:  prefix + "/action",
+			Value: string(entry.RuleAction),
+		},
+		{
+			Name:  prefix + "/protocol",
+			Value: aws.ToString(entry.Protocol),
+		},
+	}
+	if entry.PortRange != nil {
+		props = append(props,
+			&proto.Property{Name: prefix + "/from-port", Value: strconv.Itoa(int(aws.ToInt32(entry.PortRange.From)))},
+			&proto.Property{Name: prefix + "/to-port", Value: strconv.Itoa(int(aws.ToInt32(entry.PortRange.To)))},
+		)
+	}
+	if entry.CidrBlock != nil {
+		props = append(props, &proto.Property{Name: prefix + "/cidr", Value: aws.ToString(entry.CidrBlock)})
+	}
+	if entry.Ipv6CidrBlock != nil {
+		props = append(props, &proto.Property{Name: prefix + "/cidr-ipv6", Value: aws.ToString(entry.Ipv6CidrBlock)})
+	}
+	return props
+}
+
+func getNetworkACLs(ctx context.Context, client NetworkingAPI, input *ec2.DescribeNetworkAclsInput) iter.Seq2[types.NetworkAcl, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeNetworkAclsOutput, *ec2.Options] {
+		return ec2.NewDescribeNetworkAclsPaginator(client, input)
+	}, func(page *ec2.DescribeNetworkAclsOutput) []types.NetworkAcl {
+		return page.NetworkAcls
+	})
+}
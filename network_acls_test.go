@@ -0,0 +1,87 @@
+package main
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"testing"
+)
+
+func TestNaclAllowsFromInternet(t *testing.T) {
+	entry := func(number int32, action types.RuleAction, cidr, ipv6Cidr string) types.NetworkAclEntry {
+		e := types.NetworkAclEntry{
+			RuleNumber: aws.Int32(number),
+			RuleAction: action,
+			Protocol:   aws.String("6"),
+			PortRange:  &types.PortRange{From: aws.Int32(22), To: aws.Int32(22)},
+		}
+		if cidr != "" {
+			e.CidrBlock = aws.String(cidr)
+		}
+		if ipv6Cidr != "" {
+			e.Ipv6CidrBlock = aws.String(ipv6Cidr)
+		}
+		return e
+	}
+	tests := []struct {
+		name    string
+		entries []types.NetworkAclEntry
+		want    bool
+	}{
+		{
+			name: "no entries",
+		},
+		{
+			name:    "IPv4 allow",
+			entries: []types.NetworkAclEntry{entry(100, types.RuleActionAllow, internetCidrIPv4, "")},
+			want:    true,
+		},
+		{
+			name: "IPv4 deny before allow",
+			entries: []types.NetworkAclEntry{
+				entry(100, types.RuleActionDeny, internetCidrIPv4, ""),
+				entry(200, types.RuleActionAllow, internetCidrIPv4, ""),
+			},
+		},
+		{
+			name: "IPv4 deny does not stop an IPv6 allow",
+			entries: []types.NetworkAclEntry{
+				entry(100, types.RuleActionDeny, internetCidrIPv4, ""),
+				entry(200, types.RuleActionAllow, "", internetCidrIPv6),
+			},
+			want: true,
+		},
+		{
+			name: "IPv6 deny does not stop an IPv4 allow",
+			entries: []types.NetworkAclEntry{
+				entry(100, types.RuleActionDeny, "", internetCidrIPv6),
+				entry(200, types.RuleActionAllow, internetCidrIPv4, ""),
+			},
+			want: true,
+		},
+		{
+			name: "both families denied first",
+			entries: []types.NetworkAclEntry{
+				entry(100, types.RuleActionDeny, internetCidrIPv4, ""),
+				entry(110, types.RuleActionDeny, "", internetCidrIPv6),
+				entry(200, types.RuleActionAllow, internetCidrIPv4, ""),
+				entry(210, types.RuleActionAllow, "", internetCidrIPv6),
+			},
+		},
+		{
+			name: "narrower CIDRs are passed over",
+			entries: []types.NetworkAclEntry{
+				entry(100, types.RuleActionDeny, "10.0.0.0/8", ""),
+				entry(110, types.RuleActionDeny, "", "2001:db8::/32"),
+				entry(200, types.RuleActionAllow, "", internetCidrIPv6),
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := naclAllowsFromInternet(tt.entries, 22); got != tt.want {
+				t.Errorf("naclAllowsFromInternet = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var networkInsightsComponent = &proto.Component{
+	Identifier:  "common-components/amazon-vpc-reachability-analyzer",
+	Type:        "service",
+	Title:       "Amazon VPC Reachability Analyzer",
+	Description: "Amazon VPC Reachability Analyzer statically analyses network paths between a source and a destination, reporting whether the destination is reachable and through which gateways, route tables, network ACLs and security groups.",
+	Purpose:     "To verify from the network configuration itself that sensitive resources are, or are not, reachable from given sources, without sending any traffic.",
+}
+
+// networkInsightsPath is the policy input for a Reachability Analyzer path: the path as returned by the
+// EC2 API, with its most recent analysis alongside. LatestAnalysis is nil when the path was never
+// analysed.
+type networkInsightsPath struct {
+	types.NetworkInsightsPath
+	LatestAnalysis *types.NetworkInsightsAnalysis
+}
+
+func (l *CompliancePlugin) evalNetworkInsightsPaths(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	// Only the latest analysis of each path is kept. Paths are not evaluated without them, as a missing
+	// analysis would read as a path that was never analysed.
+	latestAnalyses := map[string]types.NetworkInsightsAnalysis{}
+	for analysis, err := range getNetworkInsightsAnalyses(ctx, scan.client, &ec2.DescribeNetworkInsightsAnalysesInput{}) {
+		if err != nil {
+			l.logger.Error("unable to get network insights analyses", "region", scan.region, "error", err)
+			return err
+		}
+		pathID := aws.ToString(analysis.NetworkInsightsPathId)
+		if latest, ok := latestAnalyses[pathID]; !ok || aws.ToTime(analysis.StartDate).After(aws.ToTime(latest.StartDate)) {
+			latestAnalyses[pathID] = analysis
+		}
+	}
+
+	found := 0
+	for path, err := range getNetworkInsightsPaths(ctx, scan.client, &ec2.DescribeNetworkInsightsPathsInput{}) {
+		if err != nil {
+			l.logger.Error("unable to get network insights path", "region", scan.region, "error", err)
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+			continue
+		}
+		found++
+
+		pathID := aws.ToString(path.NetworkInsightsPathId)
+		data := networkInsightsPath{NetworkInsightsPath: path}
+		if analysis, ok := latestAnalyses[pathID]; ok {
+			data.LatestAnalysis = &analysis
+		}
+
+		labels := internal.MergeMaps(scan.labels, l.tagLabels(path.Tags), map[string]string{
+			"type":                     "network-insights-path",
+			"network-insights-path-id": pathID,
+		})
+
+		props := []*proto.Property{
+			{
+				Name:  "network-insights-path-id",
+				Value: pathID,
+			},
+			{
+				Name:  "source",
+				Value: aws.ToString(path.Source),
+			},
+			{
+				Name:  "source-ip",
+				Value: aws.ToString(path.SourceIp),
+			},
+			{
+				Name:  "destination",
+				Value: aws.ToString(path.Destination),
+			},
+			{
+				Name:  "destination-ip",
+				Value: aws.ToString(path.DestinationIp),
+			},
+			{
+				Name:  "protocol",
+				Value: string(path.Protocol),
+			},
+			{
+				Name:  "analysed",
+				Value: strconv.FormatBool(data.LatestAnalysis != nil),
+			},
+		}
+		if path.DestinationPort != nil {
+			props = append(props, &proto.Property{
+				Name:  "destination-port",
+				Value: strconv.Itoa(int(aws.ToInt32(path.DestinationPort))),
+			})
+		}
+		if data.LatestAnalysis != nil {
+			props = append(props, analysisProperties(*data.LatestAnalysis)...)
+		}
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-network-insights-path/%s", pathID),
+			Type:       "network",
+			Title:      fmt.Sprintf("Amazon VPC Reachability Analyzer Path [%s]", pathID),
+			Props:      slices.Concat(props, tagProperties(path.Tags)),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, networkInsightsComponent, inventory, collectionActivities("network insights path", "DescribeNetworkInsightsPaths"), data); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	if found == 0 && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "network-insights-path", "network insights paths", collectionActivities("network insights path", "DescribeNetworkInsightsPaths"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+// analysisProperties describes the outcome of an analysis. `network-path-found` is only meaningful once
+// the analysis has `succeeded`; the path components list the IDs along the forward and return paths,
+// in sequence.
+func analysisProperties(analysis types.NetworkInsightsAnalysis) []*proto.Property {
+	return []*proto.Property{
+		{
+			Name:  "analysis-id",
+			Value: aws.ToString(analysis.NetworkInsightsAnalysisId),
+		},
+		{
+			Name:  "analysis-status",
+			Value: string(analysis.Status),
+		},
+		{
+			Name:  "analysis-start-date",
+			Value: aws.ToTime(analysis.StartDate).UTC().Format(time.RFC3339),
+		},
+		{
+			Name:  "network-path-found",
+			Value: strconv.FormatBool(aws.ToBool(analysis.NetworkPathFound)),
+		},
+		{
+			Name:  "forward-path-components",
+			Value: pathComponentIDs(analysis.ForwardPathComponents),
+		},
+		{
+			Name:  "return-path-components",
+			Value: pathComponentIDs(analysis.ReturnPathComponents),
+		},
+	}
+}
+
+// pathComponentIDs renders the components of a path as a comma-separated list of their IDs, in
+// sequence.
+func pathComponentIDs(components []types.PathComponent) string {
+	sorted := slices.Clone(components)
+	slices.SortStableFunc(sorted, func(a, b types.PathComponent) int {
+		return int(aws.ToInt32(a.SequenceNumber)) - int(aws.ToInt32(b.SequenceNumber))
+	})
+	ids := make([]string, 0, len(sorted))
+	for _, component := range sorted {
+		if component.Component != nil {
+			ids = append(ids, aws.ToString(component.Component.Id))
+		}
+	}
+	return strings.Join(ids, ",")
+}
+
+func getNetworkInsightsPaths(ctx context.Context, client NetworkingAPI, input *ec2.DescribeNetworkInsightsPathsInput) iter.Seq2[types.NetworkInsightsPath, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeNetworkInsightsPathsOutput, *ec2.Options] {
+		return ec2.NewDescribeNetworkInsightsPathsPaginator(client, input)
+	}, func(page *ec2.DescribeNetworkInsightsPathsOutput) []types.NetworkInsightsPath {
+		return page.NetworkInsightsPaths
+	})
+}
+
+func getNetworkInsightsAnalyses(ctx context.Context, client NetworkingAPI, input *ec2.DescribeNetworkInsightsAnalysesInput) iter.Seq2[types.NetworkInsightsAnalysis, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeNetworkInsightsAnalysesOutput, *ec2.Options] {
+		return ec2.NewDescribeNetworkInsightsAnalysesPaginator(client, input)
+	}, func(page *ec2.DescribeNetworkInsightsAnalysesOutput) []types.NetworkInsightsAnalysis {
+		return page.NetworkInsightsAnalyses
+	})
+}
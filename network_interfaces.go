@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"iter"
+	"strings"
+)
+
+// loadGroupInterfaces describes every network interface in the region once and indexes them by the
+// security groups attached to them.
+func (l *CompliancePlugin) loadGroupInterfaces(ctx context.Context, scan *regionScan) (map[string][]types.NetworkInterface, error) {
+	groupInterfaces := map[string][]types.NetworkInterface{}
+	for eni, err := range getNetworkInterfaces(ctx, scan.client, &ec2.DescribeNetworkInterfacesInput{}) {
+		if err != nil {
+			l.logger.Error("unable to get network interfaces", "region", scan.region, "error", err)
+			return nil, err
+		}
+		for _, group := range eni.Groups {
+			groupID := aws.ToString(group.GroupId)
+			groupInterfaces[groupID] = append(groupInterfaces[groupID], eni)
+		}
+	}
+	return groupInterfaces, nil
+}
+
+// interfaceResourceType categorises the resource a network interface belongs to, e.g. rds or
+// load-balancer, from its interface type and the description AWS services give the interfaces they
+// manage. Interfaces of instances are ec2; anything else unrecognised is other.
+func interfaceResourceType(eni types.NetworkInterface) string {
+	description := aws.ToString(eni.Description)
+	switch {
+	case eni.InterfaceType == types.NetworkInterfaceTypeLambda || strings.HasPrefix(description, "AWS Lambda VPC ENI"):
+		return "lambda"
+	case eni.InterfaceType == types.NetworkInterfaceTypeNatGateway:
+		return "nat-gateway"
+	case eni.InterfaceType == types.NetworkInterfaceTypeVpcEndpoint:
+		return "vpc-endpoint"
+	case eni.InterfaceType == types.NetworkInterfaceTypeNetworkLoadBalancer || eni.InterfaceType == types.NetworkInterfaceTypeGatewayLoadBalancer || strings.HasPrefix(description, "ELB "):
+		return "load-balancer"
+	case description == "RDSNetworkInterface":
+		return "rds"
+	case strings.HasPrefix(description, "ElastiCache"):
+		return "elasticache"
+	case strings.HasPrefix(description, "EFS mount target"):
+		return "efs"
+	case eni.Attachment != nil && aws.ToString(eni.Attachment.InstanceId) != "":
+		return "ec2"
+	}
+	return "other"
+}
+
+func getNetworkInterfaces(ctx context.Context, client NetworkingAPI, input *ec2.DescribeNetworkInterfacesInput) iter.Seq2[types.NetworkInterface, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeNetworkInterfacesOutput, *ec2.Options] {
+		return ec2.NewDescribeNetworkInterfacesPaginator(client, input)
+	}, func(page *ec2.DescribeNetworkInterfacesOutput) []types.NetworkInterface {
+		return page.NetworkInterfaces
+	})
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"iter"
+)
+
+// pager is the interface shared by the AWS SDK paginators, e.g. *ec2.DescribeSecurityGroupsPaginator,
+// whose pages are of type TOutput and whose per-call options are of type TOptions.
+type pager[TOutput any, TOptions any] interface {
+	HasMorePages() bool
+	NextPage(context.Context, ...func(TOptions)) (TOutput, error)
+}
+
+// paginate iterates over the items of every page of a paginated operation, as extracted from each page
+// by items. The paginator is built by newPaginator each time the sequence is iterated, so iterating it
+// again starts over from the first page. The context is checked before each page is requested;
+// cancellation and request errors are yielded once, with the zero item, and end the sequence.
+func paginate[TOutput, TItem, TOptions any](ctx context.Context, newPaginator func() pager[TOutput, TOptions], items func(TOutput) []TItem) iter.Seq2[TItem, error] {
+	return func(yield func(TItem, error) bool) {
+		var zero TItem
+		paginator := newPaginator()
+		for paginator.HasMorePages() {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+			result, err := paginator.NextPage(ctx)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items(result) {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
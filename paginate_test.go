@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// fakePager serves pages of ints, failing with errs[i] in place of page i when it is set.
+type fakePager struct {
+	pages    [][]int
+	errs     map[int]error
+	next     int
+	requests *int
+}
+
+func (p *fakePager) HasMorePages() bool {
+	return p.next < len(p.pages)
+}
+
+func (p *fakePager) NextPage(context.Context, ...func(*struct{})) ([]int, error) {
+	*p.requests++
+	index := p.next
+	p.next++
+	if err := p.errs[index]; err != nil {
+		return nil, err
+	}
+	return p.pages[index], nil
+}
+
+func TestPaginate(t *testing.T) {
+	errPage := errors.New("page failed")
+	tests := []struct {
+		name         string
+		pages        [][]int
+		errs         map[int]error
+		want         []int
+		wantErr      error
+		wantRequests int
+	}{
+		{
+			name:         "no pages",
+			wantRequests: 0,
+		},
+		{
+			name:         "single page",
+			pages:        [][]int{{1, 2}},
+			want:         []int{1, 2},
+			wantRequests: 1,
+		},
+		{
+			name:         "multiple pages",
+			pages:        [][]int{{1, 2}, {}, {3}, {4, 5}},
+			want:         []int{1, 2, 3, 4, 5},
+			wantRequests: 4,
+		},
+		{
+			name:         "error on the first page",
+			pages:        [][]int{{1}, {2}},
+			errs:         map[int]error{0: errPage},
+			wantErr:      errPage,
+			wantRequests: 1,
+		},
+		{
+			name:         "error on a later page",
+			pages:        [][]int{{1, 2}, {3}, {4}},
+			errs:         map[int]error{1: errPage},
+			want:         []int{1, 2},
+			wantErr:      errPage,
+			wantRequests: 2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests := 0
+			seq := paginate(context.Background(), func() pager[[]int, *struct{}] {
+				return &fakePager{pages: test.pages, errs: test.errs, requests: &requests}
+			}, func(page []int) []int {
+				return page
+			})
+
+			var got []int
+			var errs []error
+			for item, err := range seq {
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				got = append(got, item)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("items = %v, want %v", got, test.want)
+			}
+			if test.wantErr == nil && len(errs) > 0 {
+				t.Errorf("errors = %v, want none", errs)
+			}
+			if test.wantErr != nil && (len(errs) != 1 || !errors.Is(errs[0], test.wantErr)) {
+				t.Errorf("errors = %v, want %v once", errs, test.wantErr)
+			}
+			if requests != test.wantRequests {
+				t.Errorf("%d pages requested, want %d", requests, test.wantRequests)
+			}
+		})
+	}
+}
+
+func TestPaginateStopsEarly(t *testing.T) {
+	requests := 0
+	seq := paginate(context.Background(), func() pager[[]int, *struct{}] {
+		return &fakePager{pages: [][]int{{1, 2}, {3}}, requests: &requests}
+	}, func(page []int) []int {
+		return page
+	})
+
+	for item := range seq {
+		if item == 1 {
+			break
+		}
+	}
+	if requests != 1 {
+		t.Errorf("%d pages requested after breaking on the first item, want 1", requests)
+	}
+}
+
+func TestPaginateRestartsOnEachIteration(t *testing.T) {
+	requests := 0
+	seq := paginate(context.Background(), func() pager[[]int, *struct{}] {
+		return &fakePager{pages: [][]int{{1}, {2}}, requests: &requests}
+	}, func(page []int) []int {
+		return page
+	})
+
+	for range 2 {
+		var got []int
+		for item, err := range seq {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got = append(got, item)
+		}
+		if !slices.Equal(got, []int{1, 2}) {
+			t.Errorf("items = %v, want [1 2]", got)
+		}
+	}
+	if requests != 4 {
+		t.Errorf("%d pages requested over two iterations, want 4", requests)
+	}
+}
+
+func TestPaginateCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	requests := 0
+	seq := paginate(ctx, func() pager[[]int, *struct{}] {
+		return &fakePager{pages: [][]int{{1}, {2}}, requests: &requests}
+	}, func(page []int) []int {
+		return page
+	})
+
+	var got []int
+	var errs []error
+	for item, err := range seq {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		got = append(got, item)
+		cancel()
+	}
+	if !slices.Equal(got, []int{1}) {
+		t.Errorf("items = %v, want [1]", got)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], context.Canceled) {
+		t.Errorf("errors = %v, want context.Canceled once", errs)
+	}
+	if requests != 1 {
+		t.Errorf("%d pages requested, want 1", requests)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"iter"
+	"slices"
+)
+
+// managedPrefixList is the policy input for a managed prefix list and its entries.
+type managedPrefixList struct {
+	types.ManagedPrefixList
+	Entries []types.PrefixListEntry
+}
+
+var managedPrefixListComponent = &proto.Component{
+	Identifier:  "common-components/amazon-managed-prefix-list",
+	Type:        "service",
+	Title:       "Amazon VPC Managed Prefix Lists",
+	Description: "Amazon VPC managed prefix lists are named, versioned sets of CIDR blocks that can be referenced from security group rules and route tables in place of individual CIDRs. They are either customer-managed or AWS-managed for AWS service address ranges.",
+	Purpose:     "To centrally define and audit the address ranges trusted by network rules, so that changes to a trusted range are reviewed once and applied consistently.",
+}
+
+// evalManagedPrefixLists evaluates every managed prefix list and records its CIDRs on the scan so
+// security group rules referencing the list can be resolved.
+func (l *CompliancePlugin) evalManagedPrefixLists(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	found := 0
+	for prefixList, err := range getManagedPrefixLists(ctx, scan.client, &ec2.DescribeManagedPrefixListsInput{}) {
+		if err != nil {
+			l.logger.Error("unable to get managed prefix list", "region", scan.region, "error", err)
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+			continue
+		}
+		found++
+
+		prefixListID := aws.ToString(prefixList.PrefixListId)
+		data := managedPrefixList{
+			ManagedPrefixList: prefixList,
+			Entries:           make([]types.PrefixListEntry, 0),
+		}
+		entriesInput := &ec2.GetManagedPrefixListEntriesInput{
+			PrefixListId: prefixList.PrefixListId,
+		}
+		for entry, err := range getManagedPrefixListEntries(ctx, scan.client, entriesInput) {
+			if err != nil {
+				l.logger.Error("unable to get managed prefix list entries", "region", scan.region, "prefix-list-id", prefixListID, "error", err)
+				accumulatedErrors = errors.Join(accumulatedErrors, err)
+				continue
+			}
+			data.Entries = append(data.Entries, entry)
+			scan.prefixListCIDRs[prefixListID] = append(scan.prefixListCIDRs[prefixListID], aws.ToString(entry.Cidr))
+		}
+
+		labels := internal.MergeMaps(scan.labels, l.tagLabels(prefixList.Tags), map[string]string{
+			"type":           "managed-prefix-list",
+			"prefix-list-id": prefixListID,
+		})
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-managed-prefix-list/%s", prefixListID),
+			Type:       "network",
+			Title:      fmt.Sprintf("Amazon Managed Prefix List [%s]", prefixListID),
+			Props: slices.Concat([]*proto.Property{
+				{
+					Name:  "prefix-list-id",
+					Value: prefixListID,
+				},
+				{
+					Name:  "prefix-list-name",
+					Value: aws.ToString(prefixList.PrefixListName),
+				},
+				{
+					Name:  "address-family",
+					Value: aws.ToString(prefixList.AddressFamily),
+				},
+				{
+					Name:  "owner-id",
+					Value: aws.ToString(prefixList.OwnerId),
+				},
+			}, prefixListEntryProperties(data.Entries), tagProperties(prefixList.Tags)),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, managedPrefixListComponent, inventory, collectionActivities("managed prefix list", "DescribeManagedPrefixLists"), data); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	if found == 0 && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "managed-prefix-list", "managed prefix lists", collectionActivities("managed prefix list", "DescribeManagedPrefixLists"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+// loadPrefixListCIDRs records the CIDRs of every managed prefix list on the scan, without evaluating
+// the lists, for security groups collected without managed-prefix-lists. Nothing is recorded unless
+// every list could be read, so a rule is never linked to only some of a list's CIDRs.
+func (l *CompliancePlugin) loadPrefixListCIDRs(ctx context.Context, scan *regionScan) error {
+	cidrs := map[string][]string{}
+	for prefixList, err := range getManagedPrefixLists(ctx, scan.client, &ec2.DescribeManagedPrefixListsInput{}) {
+		if err != nil {
+			return err
+		}
+		prefixListID := aws.ToString(prefixList.PrefixListId)
+		entriesInput := &ec2.GetManagedPrefixListEntriesInput{
+			PrefixListId: prefixList.PrefixListId,
+		}
+		for entry, err := range getManagedPrefixListEntries(ctx, scan.client, entriesInput) {
+			if err != nil {
+				return err
+			}
+			cidrs[prefixListID] = append(cidrs[prefixListID], aws.ToString(entry.Cidr))
+		}
+	}
+	scan.prefixListCIDRs = cidrs
+	return nil
+}
+
+// prefixListEntryProperties renders each entry as `entry/<n>/cidr` and, when set,
+// `entry/<n>/description` properties.
+func prefixListEntryProperties(entries []types.PrefixListEntry) []*proto.Property {
+	props := make([]*proto.Property, 0)
+	for i, entry := range entries {
+		props = append(props, &proto.Property{Name: fmt.Sprintf("entry/%d/cidr", i), Value: aws.ToString(entry.Cidr)})
+		if entry.Description != nil {
+			props = append(props, &proto.Property{Name: fmt.Sprintf("entry/%d/description", i), Value: aws.ToString(entry.Description)})
+		}
+	}
+	return props
+}
+
+func getManagedPrefixLists(ctx context.Context, client NetworkingAPI, input *ec2.DescribeManagedPrefixListsInput) iter.Seq2[types.ManagedPrefixList, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeManagedPrefixListsOutput, *ec2.Options] {
+		return ec2.NewDescribeManagedPrefixListsPaginator(client, input)
+	}, func(page *ec2.DescribeManagedPrefixListsOutput) []types.ManagedPrefixList {
+		return page.PrefixLists
+	})
+}
+
+func getManagedPrefixListEntries(ctx context.Context, client NetworkingAPI, input *ec2.GetManagedPrefixListEntriesInput) iter.Seq2[types.PrefixListEntry, error] {
+	return paginate(ctx, func() pager[*ec2.GetManagedPrefixListEntriesOutput, *ec2.Options] {
+		return ec2.NewGetManagedPrefixListEntriesPaginator(client, input)
+	}, func(page *ec2.GetManagedPrefixListEntriesOutput) []types.PrefixListEntry {
+		return page.Entries
+	})
+}
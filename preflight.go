@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"slices"
+)
+
+// credentialErrorCodes are the AWS error codes returned when the caller's credentials are invalid or
+// have expired.
+var credentialErrorCodes = []string{"InvalidClientTokenId", "UnrecognizedClientException", "ExpiredToken", "ExpiredTokenException", "AuthFailure", "SignatureDoesNotMatch", "InvalidAccessKeyId"}
+
+// isCredentialError reports whether err is caused by missing, invalid or expired credentials.
+func isCredentialError(err error) bool {
+	var signingErr *v4.SigningError
+	if errors.As(err, &signingErr) {
+		return true
+	}
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && slices.Contains(credentialErrorCodes, apiErr.ErrorCode())
+}
+
+// preflight checks in region that the plugin's credentials work and, when security groups are
+// collected, allow them to be described, so a run that cannot succeed fails before any region is
+// scanned. Other failures, such as timeouts, are only logged and left for the scan itself to report.
+func (l *CompliancePlugin) preflight(ctx context.Context, region string) error {
+	cfg, err := l.loadAWSConfig(ctx, region)
+	if err != nil {
+		return fmt.Errorf("preflight: unable to load AWS configuration: %w", err)
+	}
+
+	identity, err := l.newIdentityClient(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	switch {
+	case err == nil:
+		l.logger.Info("Authenticated to AWS", "account", aws.ToString(identity.Account), "arn", aws.ToString(identity.Arn), "user-id", aws.ToString(identity.UserId))
+	case isCredentialError(err) || isAccessDenied(err):
+		return fmt.Errorf("preflight: the configured AWS credentials are not valid in %s, check the profile, environment or assumed roles: %w", region, err)
+	default:
+		l.logger.Warn("preflight: unable to resolve caller identity", "region", region, "error", err)
+	}
+
+	// Roles scoped to other resource types need not be allowed to describe security groups.
+	if !l.resources["security-groups"] {
+		return nil
+	}
+	_, err = l.newNetworkingClient(cfg).DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{MaxResults: aws.Int32(5)})
+	switch {
+	case err == nil:
+	case isCredentialError(err):
+		return fmt.Errorf("preflight: the configured AWS credentials are not valid in %s, check the profile, environment or assumed roles: %w", region, err)
+	case isAccessDenied(err):
+		return fmt.Errorf("preflight: insufficient permissions, ec2:DescribeSecurityGroups is denied in %s: %w", region, err)
+	default:
+		l.logger.Warn("preflight: unable to describe security groups", "region", region, "error", err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/ram"
+	ramtypes "github.com/aws/aws-sdk-go-v2/service/ram/types"
+	"github.com/compliance-framework/agent/runner/proto"
+	"iter"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// accountIDPrincipal matches RAM principals that are AWS account IDs, rather than organization, OU or
+// IAM ARNs.
+var accountIDPrincipal = regexp.MustCompile(`^[0-9]{12}$`)
+
+// ramShare is a principal a security group is shared with through a RAM resource share. External is
+// true when the principal is outside the account's organization.
+type ramShare struct {
+	ResourceShareArn string
+	Principal        string
+	External         bool
+}
+
+// loadGroupShares lists the account's resources shared through AWS Resource Access Manager and returns
+// the principals each security group is shared with, indexed by group ID. Groups that are not shared
+// are absent. When RAM cannot be queried for lack of permissions, nil is returned without error, so
+// sharing is omitted from the evidence rather than reported as absent.
+func (l *CompliancePlugin) loadGroupShares(ctx context.Context, scan *regionScan) (map[string][]ramShare, error) {
+	shareGroups := map[string][]string{}
+	input := &ram.ListResourcesInput{ResourceOwner: ramtypes.ResourceOwnerSelf}
+	for resource, err := range getSharedResources(ctx, scan.sharing, input) {
+		if err != nil {
+			if isAccessDenied(err) {
+				l.logger.Warn("unable to list resources shared through RAM, omitting sharing from security groups", "region", scan.region, "error", err)
+				return nil, nil
+			}
+			l.logger.Error("unable to list resources shared through RAM", "region", scan.region, "error", err)
+			return nil, err
+		}
+		parsed, err := arn.Parse(aws.ToString(resource.Arn))
+		if err != nil || resource.Status != ramtypes.ResourceStatusAvailable {
+			continue
+		}
+		if groupID, ok := strings.CutPrefix(parsed.Resource, "security-group/"); ok {
+			shareArn := aws.ToString(resource.ResourceShareArn)
+			shareGroups[shareArn] = append(shareGroups[shareArn], groupID)
+		}
+	}
+
+	groupShares := map[string][]ramShare{}
+	if len(shareGroups) == 0 {
+		return groupShares, nil
+	}
+	associationsInput := &ram.GetResourceShareAssociationsInput{
+		AssociationType:   ramtypes.ResourceShareAssociationTypePrincipal,
+		AssociationStatus: ramtypes.ResourceShareAssociationStatusAssociated,
+	}
+	for association, err := range getResourceShareAssociations(ctx, scan.sharing, associationsInput) {
+		if err != nil {
+			l.logger.Error("unable to get RAM resource share associations", "region", scan.region, "error", err)
+			return nil, err
+		}
+		shareArn := aws.ToString(association.ResourceShareArn)
+		for _, groupID := range shareGroups[shareArn] {
+			groupShares[groupID] = append(groupShares[groupID], ramShare{
+				ResourceShareArn: shareArn,
+				Principal:        aws.ToString(association.AssociatedEntity),
+				External:         aws.ToBool(association.External),
+			})
+		}
+	}
+	return groupShares, nil
+}
+
+// ramSharingProperties reports whether the group is shared through RAM and with whom. Nothing is
+// reported when groupShares is nil, i.e. when RAM could not be queried.
+func ramSharingProperties(group types.SecurityGroup, groupShares map[string][]ramShare) []*proto.Property {
+	if groupShares == nil {
+		return nil
+	}
+	shares := groupShares[aws.ToString(group.GroupId)]
+	principals := make([]string, 0, len(shares))
+	accountIDs := make([]string, 0)
+	ouIDs := make([]string, 0)
+	external := false
+	for _, share := range shares {
+		principals = append(principals, share.Principal)
+		external = external || share.External
+		if accountIDPrincipal.MatchString(share.Principal) {
+			accountIDs = append(accountIDs, share.Principal)
+		} else if parsed, err := arn.Parse(share.Principal); err == nil && parsed.Service == "organizations" && strings.HasPrefix(parsed.Resource, "ou/") {
+			ouIDs = append(ouIDs, parsed.Resource[strings.LastIndex(parsed.Resource, "/")+1:])
+		}
+	}
+	for _, ids := range []*[]string{&principals, &accountIDs, &ouIDs} {
+		slices.Sort(*ids)
+		*ids = slices.Compact(*ids)
+	}
+
+	return []*proto.Property{
+		{
+			Name:  "shared-via-ram",
+			Value: strconv.FormatBool(len(shares) > 0),
+		},
+		{
+			Name:  "ram-shared-with",
+			Value: strings.Join(principals, ","),
+		},
+		{
+			Name:  "ram-shared-account-ids",
+			Value: strings.Join(accountIDs, ","),
+		},
+		{
+			Name:  "ram-shared-ou-ids",
+			Value: strings.Join(ouIDs, ","),
+		},
+		{
+			Name:  "ram-shared-outside-organization",
+			Value: strconv.FormatBool(external),
+		},
+	}
+}
+
+func getSharedResources(ctx context.Context, client SharingAPI, input *ram.ListResourcesInput) iter.Seq2[ramtypes.Resource, error] {
+	return paginate(ctx, func() pager[*ram.ListResourcesOutput, *ram.Options] {
+		return ram.NewListResourcesPaginator(client, input)
+	}, func(page *ram.ListResourcesOutput) []ramtypes.Resource {
+		return page.Resources
+	})
+}
+
+func getResourceShareAssociations(ctx context.Context, client SharingAPI, input *ram.GetResourceShareAssociationsInput) iter.Seq2[ramtypes.ResourceShareAssociation, error] {
+	return paginate(ctx, func() pager[*ram.GetResourceShareAssociationsOutput, *ram.Options] {
+		return ram.NewGetResourceShareAssociationsPaginator(client, input)
+	}, func(page *ram.GetResourceShareAssociationsOutput) []ramtypes.ResourceShareAssociation {
+		return page.ResourceShareAssociations
+	})
+}
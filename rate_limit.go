@@ -0,0 +1,31 @@
+package main
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"golang.org/x/time/rate"
+	"net/http"
+)
+
+// rateLimitedClient waits on a limiter shared by every client of the plugin before each HTTP request,
+// so the combined request rate of all regions scanned concurrently stays bounded. It sits below the
+// SDK's retryer, so retried attempts are limited too.
+type rateLimitedClient struct {
+	client  aws.HTTPClient
+	limiter *rate.Limiter
+}
+
+func (c *rateLimitedClient) Do(req *http.Request) (*http.Response, error) {
+	if err := c.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return c.client.Do(req)
+}
+
+// newRateLimiter returns a token bucket allowing requestsPerSecond requests per second, with bursts of
+// up to one second's worth of requests. It returns nil, meaning unlimited, for a rate of zero.
+func newRateLimiter(requestsPerSecond float64) *rate.Limiter {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(requestsPerSecond), max(int(requestsPerSecond), 1))
+}
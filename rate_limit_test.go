@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal/awsmock"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestRateLimiterBoundsCombinedRate sends requests from the clients of two regions at once, and checks
+// they are limited together to api_rate_limit.
+func TestRateLimiterBoundsCombinedRate(t *testing.T) {
+	const rateLimit, requests = 100, 150
+	server, received := throttlingServer(t, 0)
+	plugin := newTestPlugin(t, map[string]string{
+		"regions":        "us-east-1,eu-west-1",
+		"endpoint_url":   server.URL,
+		"api_rate_limit": "100",
+	}, &awsmock.EC2{})
+
+	var clients []*ec2.Client
+	for _, region := range plugin.config.Regions {
+		cfg, err := plugin.loadAWSConfig(context.Background(), region)
+		if err != nil {
+			t.Fatalf("loadAWSConfig(%s): %v", region, err)
+		}
+		clients = append(clients, ec2.NewFromConfig(cfg))
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := clients[i%len(clients)].DescribeSecurityGroups(context.Background(), &ec2.DescribeSecurityGroupsInput{}); err != nil {
+				t.Errorf("DescribeSecurityGroups: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	if got := received.Load(); got != requests {
+		t.Errorf("%d requests received, want %d", got, requests)
+	}
+	// The burst of one second's worth of requests is served at once, the rest at rateLimit per second.
+	if minimum := time.Duration(requests-rateLimit) * time.Second / rateLimit; elapsed < minimum*9/10 {
+		t.Errorf("%d requests took %s, want at least %s at %d requests per second", requests, elapsed, minimum, rateLimit)
+	}
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	if limiter := newRateLimiter(0); limiter != nil {
+		t.Errorf("newRateLimiter(0) = %v, want nil", limiter)
+	}
+	for _, test := range []struct {
+		rate  float64
+		burst int
+	}{
+		{rate: 0.5, burst: 1},
+		{rate: 1, burst: 1},
+		{rate: 25, burst: 25},
+	} {
+		limiter := newRateLimiter(test.rate)
+		if float64(limiter.Limit()) != test.rate || limiter.Burst() != test.burst {
+			t.Errorf("newRateLimiter(%v) allows %v per second with bursts of %d, want bursts of %d", test.rate, limiter.Limit(), limiter.Burst(), test.burst)
+		}
+	}
+}
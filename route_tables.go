@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var routeTableComponent = &proto.Component{
+	Identifier:  "common-components/amazon-vpc-route-table",
+	Type:        "service",
+	Title:       "Amazon VPC Route Tables",
+	Description: "Amazon VPC route tables contain the routes that determine where network traffic from associated subnets and gateways is directed, such as to an internet gateway, NAT gateway, peering connection or transit gateway.",
+	Purpose:     "To control the network paths available to resources in a VPC, ensuring private subnets have no direct route to the internet and traffic only flows through approved gateways.",
+}
+
+func (l *CompliancePlugin) evalRouteTables(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	input := &ec2.DescribeRouteTablesInput{
+		Filters: l.vpcFilters(),
+	}
+
+	found := 0
+	for routeTable, err := range getRouteTables(ctx, scan.client, input) {
+		if err != nil {
+			l.logger.Error("unable to get route table", "region", scan.region, "error", err)
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+			continue
+		}
+		if scan.excludesVpc(aws.ToString(routeTable.VpcId)) {
+			continue
+		}
+		found++
+
+		routeTableID := aws.ToString(routeTable.RouteTableId)
+		labels := internal.MergeMaps(scan.labels, l.tagLabels(routeTable.Tags), map[string]string{
+			"type":           "route-table",
+			"route-table-id": routeTableID,
+			"_vpc-id":        aws.ToString(routeTable.VpcId),
+		})
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-route-table/%s", routeTableID),
+			Type:       "network",
+			Title:      fmt.Sprintf("Amazon VPC Route Table [%s]", routeTableID),
+			Props: slices.Concat([]*proto.Property{
+				{
+					Name:  "route-table-id",
+					Value: routeTableID,
+				},
+				{
+					Name:  "vpc-id",
+					Value: aws.ToString(routeTable.VpcId),
+				},
+				{
+					Name:  "has-igw-default-route",
+					Value: strconv.FormatBool(hasInternetGatewayDefaultRoute(routeTable)),
+				},
+			}, routeProperties(routeTable.Routes), routeAssociationProperties(routeTable.Associations), tagProperties(routeTable.Tags)),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, routeTableComponent, inventory, collectionActivities("route table", "DescribeRouteTables"), routeTable); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	if found == 0 && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "route-table", "route tables", collectionActivities("route table", "DescribeRouteTables"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+// routeDestination returns the IPv4 CIDR, IPv6 CIDR or prefix list a route applies to.
+func routeDestination(route types.Route) string {
+	switch {
+	case route.DestinationCidrBlock != nil:
+		return aws.ToString(route.DestinationCidrBlock)
+	case route.DestinationIpv6CidrBlock != nil:
+		return aws.ToString(route.DestinationIpv6CidrBlock)
+	default:
+		return aws.ToString(route.DestinationPrefixListId)
+	}
+}
+
+// routeTarget classifies where a route sends traffic, returning a short target type (igw, nat,
+// pcx, tgw, ...) and the target's ID.
+func routeTarget(route types.Route) (string, string) {
+	switch {
+	case route.NatGatewayId != nil:
+		return "nat", aws.ToString(route.NatGatewayId)
+	case route.TransitGatewayId != nil:
+		return "tgw", aws.ToString(route.TransitGatewayId)
+	case route.VpcPeeringConnectionId != nil:
+		return "pcx", aws.ToString(route.VpcPeeringConnectionId)
+	case route.EgressOnlyInternetGatewayId != nil:
+		return "eigw", aws.ToString(route.EgressOnlyInternetGatewayId)
+	case route.NetworkInterfaceId != nil:
+		return "eni", aws.ToString(route.NetworkInterfaceId)
+	case route.InstanceId != nil:
+		return "instance", aws.ToString(route.InstanceId)
+	case route.CarrierGatewayId != nil:
+		return "cagw", aws.ToString(route.CarrierGatewayId)
+	case route.LocalGatewayId != nil:
+		return "lgw", aws.ToString(route.LocalGatewayId)
+	case route.CoreNetworkArn != nil:
+		return "core-network", aws.ToString(route.CoreNetworkArn)
+	case route.GatewayId != nil:
+		// GatewayId holds internet, virtual private and VPC endpoint gateways, as well as "local".
+		gatewayID := aws.ToString(route.GatewayId)
+		if gatewayID == "local" {
+			return "local", gatewayID
+		}
+		if prefix, _, found := strings.Cut(gatewayID, "-"); found {
+			return prefix, gatewayID
+		}
+		return "gateway", gatewayID
+	default:
+		return "unknown", ""
+	}
+}
+
+// hasInternetGatewayDefaultRoute reports whether the table sends 0.0.0.0/0 or ::/0 to an internet
+// gateway.
+func hasInternetGatewayDefaultRoute(routeTable types.RouteTable) bool {
+	for _, route := range routeTable.Routes {
+		destination := routeDestination(route)
+		targetType, _ := routeTarget(route)
+		if targetType == "igw" && (destination == internetCidrIPv4 || destination == internetCidrIPv6) {
+			return true
+		}
+	}
+	return false
+}
+
+// routeProperties renders each route as `route/<n>/<field>` properties.
+func routeProperties(routes []types.Route) []*proto.Property {
+	props := make([]*proto.Property, 0)
+	for i, route := range routes {
+		prefix := fmt.Sprintf("route/%d", i)
+		targetType, target := routeTarget(route)
+		props = append(props,
+			&proto.Property{Name: prefix + "/destination", Value: routeDestination(route)},
+			&proto.Property{Name: prefix + "/target-type", Value: targetType},
+			&proto.Property{Name: prefix + "/target", Value: target},
+			&proto.Property{Name: prefix + "/state", Value: string(route.State)},
+		)
+	}
+	return props
+}
+
+// routeAssociationProperties renders the subnets and gateways a table is associated with as
+// `association/<n>/<field>` properties, and flags the VPC's main route table with `is-main`.
+func routeAssociationProperties(associations []types.RouteTableAssociation) []*proto.Property {
+	props := make([]*proto.Property, 0)
+	isMain := false
+	for i, association := range associations {
+		prefix := fmt.Sprintf("association/%d", i)
+		if aws.ToBool(association.Main) {
+			isMain = true
+		}
+		if association.SubnetId != nil {
+			props = append(props, &proto.Property{Name: prefix + "/subnet-id", Value: aws.ToString(association.SubnetId)})
+		}
+		if association.GatewayId != nil {
+			props = append(props, &proto.Property{Name: prefix + "/gateway-id", Value: aws.ToString(association.GatewayId)})
+		}
+	}
+	return append(props, &proto.Property{Name: "is-main", Value: strconv.FormatBool(isMain)})
+}
+
+func getRouteTables(ctx context.Context, client NetworkingAPI, input *ec2.DescribeRouteTablesInput) iter.Seq2[types.RouteTable, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeRouteTablesOutput, *ec2.Options] {
+		return ec2.NewDescribeRouteTablesPaginator(client, input)
+	}, func(page *ec2.DescribeRouteTablesOutput) []types.RouteTable {
+		return page.RouteTables
+	})
+}
@@ -0,0 +1,485 @@
+package main
+
+import (
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	ruleDirectionIngress = "ingress"
+	ruleDirectionEgress  = "egress"
+
+	internetCidrIPv4 = "0.0.0.0/0"
+	internetCidrIPv6 = "::/0"
+
+	// allProtocols is the normalised protocol name for IpProtocol "-1", which AWS uses to mean every
+	// protocol on every port.
+	allProtocols = "all"
+)
+
+// defaultSensitivePorts are the administrative and data store ports reported when open to the
+// internet, unless overridden by the sensitive_ports config key: FTP (20, 21), SSH (22), Telnet (23),
+// SMTP (25), RPC and SMB (135, 139, 445), MSSQL (1433), Oracle (1521), Docker (2375, 2376), etcd
+// (2379), MySQL (3306), RDP (3389), PostgreSQL (5432), VNC (5900), Redis (6379), Elasticsearch
+// (9200), Memcached (11211) and MongoDB (27017).
+var defaultSensitivePorts = []int32{20, 21, 22, 23, 25, 135, 139, 445, 1433, 1521, 2375, 2376, 2379, 3306, 3389, 5432, 5900, 6379, 9200, 11211, 27017}
+
+// securityGroupRule is a single source or destination within an IpPermission. AWS groups several
+// CIDRs and group references under one permission; expanding them gives one entry per grant, which
+// is how AWS counts rules and how policies usually want to reason about them.
+type securityGroupRule struct {
+	Direction           string
+	Protocol            string
+	FromPort            int32
+	ToPort              int32
+	CidrIPv4            string
+	CidrIPv6            string
+	ReferencedGroupID   string
+	ReferencedGroupName string
+	ReferencedUserID    string
+	PrefixListID        string
+	PrefixListCIDRs     []string
+
+	// Description is the description of the individual range, group reference or prefix list, and is
+	// empty when none was set.
+	Description string
+
+	// RuleID and RuleTags are only known when rules are read through the DescribeSecurityGroupRules API.
+	RuleID   string
+	RuleTags []types.Tag
+}
+
+// ruleLookups holds the data used to resolve references made by security group rules.
+type ruleLookups struct {
+	// groupNames maps known security group IDs to their names.
+	groupNames map[string]string
+	// prefixListCIDRs maps managed prefix list IDs to the CIDRs they contain.
+	prefixListCIDRs map[string][]string
+	// groupRules maps security group IDs to the rules returned by the DescribeSecurityGroupRules API.
+	// It is nil unless use_security_group_rules_api is enabled.
+	groupRules map[string][]types.SecurityGroupRule
+}
+
+// expandRules flattens a set of permissions into one securityGroupRule per CIDR, IPv6 range,
+// referenced group and prefix list.
+func expandRules(direction string, permissions []types.IpPermission) []securityGroupRule {
+	rules := make([]securityGroupRule, 0)
+	for _, permission := range permissions {
+		base := securityGroupRule{
+			Direction: direction,
+			Protocol:  aws.ToString(permission.IpProtocol),
+			FromPort:  aws.ToInt32(permission.FromPort),
+			ToPort:    aws.ToInt32(permission.ToPort),
+		}
+		if base.Protocol == "-1" {
+			base.Protocol = allProtocols
+			base.FromPort = 0
+			base.ToPort = 65535
+		}
+
+		for _, ipRange := range permission.IpRanges {
+			rule := base
+			rule.CidrIPv4 = aws.ToString(ipRange.CidrIp)
+			rule.Description = aws.ToString(ipRange.Description)
+			rules = append(rules, rule)
+		}
+		for _, ipv6Range := range permission.Ipv6Ranges {
+			rule := base
+			rule.CidrIPv6 = aws.ToString(ipv6Range.CidrIpv6)
+			rule.Description = aws.ToString(ipv6Range.Description)
+			rules = append(rules, rule)
+		}
+		for _, pair := range permission.UserIdGroupPairs {
+			rule := base
+			rule.ReferencedGroupID = aws.ToString(pair.GroupId)
+			rule.ReferencedGroupName = aws.ToString(pair.GroupName)
+			rule.ReferencedUserID = aws.ToString(pair.UserId)
+			rule.Description = aws.ToString(pair.Description)
+			rules = append(rules, rule)
+		}
+		for _, prefixList := range permission.PrefixListIds {
+			rule := base
+			rule.PrefixListID = aws.ToString(prefixList.PrefixListId)
+			rule.Description = aws.ToString(prefixList.Description)
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// properties renders the rule as evidence properties named `<direction>-rule/<index>/<field>`.
+func (r securityGroupRule) properties(index int) []*proto.Property {
+	prefix := fmt.Sprintf("%s-rule/%d", r.Direction, index)
+	props := []*proto.Property{
+		{
+			Name:  prefix + "/protocol",
+			Value: r.Protocol,
+		},
+		{
+			Name:  prefix + "/from-port",
+			Value: strconv.Itoa(int(r.FromPort)),
+		},
+		{
+			Name:  prefix + "/to-port",
+			Value: strconv.Itoa(int(r.ToPort)),
+		},
+	}
+
+	switch {
+	case r.CidrIPv4 != "":
+		props = append(props, &proto.Property{Name: prefix + "/cidr", Value: r.CidrIPv4})
+	case r.CidrIPv6 != "":
+		props = append(props, &proto.Property{Name: prefix + "/cidr-ipv6", Value: r.CidrIPv6})
+	case r.ReferencedGroupID != "":
+		props = append(props, &proto.Property{Name: prefix + "/referenced-group-id", Value: r.ReferencedGroupID})
+		if r.ReferencedGroupName != "" {
+			props = append(props, &proto.Property{Name: prefix + "/referenced-group-name", Value: r.ReferencedGroupName})
+		}
+		if r.ReferencedUserID != "" {
+			props = append(props, &proto.Property{Name: prefix + "/referenced-user-id", Value: r.ReferencedUserID})
+		}
+	case r.PrefixListID != "":
+		props = append(props, &proto.Property{Name: prefix + "/prefix-list-id", Value: r.PrefixListID})
+		if len(r.PrefixListCIDRs) > 0 {
+			props = append(props, &proto.Property{Name: prefix + "/prefix-list-cidrs", Value: strings.Join(r.PrefixListCIDRs, ",")})
+		}
+	}
+
+	if r.RuleID != "" {
+		props = append(props, &proto.Property{Name: prefix + "/rule-id", Value: r.RuleID})
+	}
+	if r.Description != "" {
+		props = append(props, &proto.Property{Name: prefix + "/description", Value: r.Description})
+	}
+	for _, tag := range r.RuleTags {
+		props = append(props, &proto.Property{Name: prefix + "/tag/" + aws.ToString(tag.Key), Value: aws.ToString(tag.Value)})
+	}
+
+	return props
+}
+
+// ruleProperties expands every ingress and egress rule of a group into evidence properties.
+// References are resolved through lookups; references that cannot be resolved, such as cross-account
+// or cross-VPC groups, are reported by ID only.
+func ruleProperties(group types.SecurityGroup, lookups ruleLookups) []*proto.Property {
+	apiRules := lookups.groupRules[aws.ToString(group.GroupId)]
+	props := make([]*proto.Property, 0)
+	for i, rule := range expandRules(ruleDirectionIngress, group.IpPermissions) {
+		props = append(props, rule.resolve(lookups).annotate(apiRules).properties(i)...)
+	}
+	for i, rule := range expandRules(ruleDirectionEgress, group.IpPermissionsEgress) {
+		props = append(props, rule.resolve(lookups).annotate(apiRules).properties(i)...)
+	}
+	return props
+}
+
+// ruleCountProperties counts the group's rules in each direction as AWS does towards the rules per
+// group quota: every CIDR, IPv6 range, referenced group and prefix list of a permission is one rule.
+// It also reports whether the group is empty, see isEmptySecurityGroup.
+func ruleCountProperties(group types.SecurityGroup) []*proto.Property {
+	return []*proto.Property{
+		{
+			Name:  "ingress-rule-count",
+			Value: strconv.Itoa(len(expandRules(ruleDirectionIngress, group.IpPermissions))),
+		},
+		{
+			Name:  "egress-rule-count",
+			Value: strconv.Itoa(len(expandRules(ruleDirectionEgress, group.IpPermissionsEgress))),
+		},
+		{
+			Name:  "empty-security-group",
+			Value: strconv.FormatBool(isEmptySecurityGroup(group)),
+		},
+	}
+}
+
+// isEmptySecurityGroup reports whether group has no ingress rules and no egress rules other than the
+// allow-all rule AWS adds by default, so no traffic can reach its resources.
+func isEmptySecurityGroup(group types.SecurityGroup) bool {
+	return len(group.IpPermissions) == 0 && !slices.ContainsFunc(expandRules(ruleDirectionEgress, group.IpPermissionsEgress), func(rule securityGroupRule) bool {
+		return !rule.isDefaultEgress()
+	})
+}
+
+// resolve fills in the name of a referenced group, when AWS did not return one, and the CIDRs of a
+// referenced prefix list.
+func (r securityGroupRule) resolve(lookups ruleLookups) securityGroupRule {
+	if r.ReferencedGroupID != "" && r.ReferencedGroupName == "" {
+		r.ReferencedGroupName = lookups.groupNames[r.ReferencedGroupID]
+	}
+	if r.PrefixListID != "" {
+		r.PrefixListCIDRs = lookups.prefixListCIDRs[r.PrefixListID]
+	}
+	return r
+}
+
+// isOpenToInternet reports whether the rule grants 0.0.0.0/0 or ::/0.
+func (r securityGroupRule) isOpenToInternet() bool {
+	return r.CidrIPv4 == internetCidrIPv4 || r.CidrIPv6 == internetCidrIPv6
+}
+
+// isOpenToUnapprovedCidr reports whether the rule grants a public CIDR, IPv4 or IPv6, that does not lie
+// within any of approved. Group references, prefix lists and unparseable CIDRs are never reported.
+func (r securityGroupRule) isOpenToUnapprovedCidr(approved []netip.Prefix) bool {
+	prefix, ok := r.cidrPrefix()
+	if !ok || !internal.IsPublicPrefix(prefix) {
+		return false
+	}
+	return !slices.ContainsFunc(approved, func(approvedPrefix netip.Prefix) bool {
+		return internal.PrefixContains(approvedPrefix, prefix)
+	})
+}
+
+// coversPort reports whether the rule grants traffic to port. Only TCP, UDP and all-protocol rules
+// carry ports; for ICMP the range holds types and codes instead.
+func (r securityGroupRule) coversPort(port int32) bool {
+	switch r.Protocol {
+	case "tcp", "udp", "6", "17", allProtocols:
+		return internal.PortInRange(r.FromPort, r.ToPort, port)
+	}
+	return false
+}
+
+// portRange renders the rule's ports as "<port>" or "<from>-<to>".
+func (r securityGroupRule) portRange() string {
+	if r.FromPort == r.ToPort {
+		return strconv.Itoa(int(r.FromPort))
+	}
+	return fmt.Sprintf("%d-%d", r.FromPort, r.ToPort)
+}
+
+// openPortRanges returns the distinct port ranges, in rule order, of the rules matching open.
+func openPortRanges(rules []securityGroupRule, open func(securityGroupRule) bool) []string {
+	ranges := make([]string, 0)
+	for _, rule := range rules {
+		if open(rule) && !slices.Contains(ranges, rule.portRange()) {
+			ranges = append(ranges, rule.portRange())
+		}
+	}
+	return ranges
+}
+
+// exposureProperties computes internet exposure signals from a group's ingress rules, listing which
+// of sensitivePorts are open to the internet and whether any public CIDR outside approvedCIDRs is granted.
+func exposureProperties(group types.SecurityGroup, sensitivePorts []int32, approvedCIDRs []netip.Prefix) []*proto.Property {
+	ingress := expandRules(ruleDirectionIngress, group.IpPermissions)
+
+	openPorts := openPortRanges(ingress, securityGroupRule.isOpenToInternet)
+	props := []*proto.Property{
+		{
+			Name:  "open-to-internet",
+			Value: strconv.FormatBool(len(openPorts) > 0),
+		},
+	}
+	if len(openPorts) > 0 {
+		props = append(props, &proto.Property{
+			Name:  "open-to-internet-ports",
+			Value: strings.Join(openPorts, ","),
+		})
+	}
+
+	exposedPorts := make([]string, 0)
+	for _, port := range sensitivePorts {
+		if slices.ContainsFunc(ingress, func(r securityGroupRule) bool {
+			return r.isOpenToInternet() && r.coversPort(port)
+		}) {
+			exposedPorts = append(exposedPorts, strconv.Itoa(int(port)))
+		}
+	}
+	if len(exposedPorts) > 0 {
+		props = append(props, &proto.Property{
+			Name:  "open-to-internet-sensitive-ports",
+			Value: strings.Join(exposedPorts, ","),
+		})
+	}
+
+	// A rule for every protocol opens all ports, including ICMP, and is reported separately from wide
+	// port ranges so it can be treated as the most severe exposure.
+	allTraffic := slices.ContainsFunc(ingress, func(r securityGroupRule) bool {
+		return r.Protocol == allProtocols && r.isOpenToInternet()
+	})
+	props = append(props, &proto.Property{
+		Name:  "all-traffic-open",
+		Value: strconv.FormatBool(allTraffic),
+	})
+
+	props = append(props, &proto.Property{
+		Name: "open-to-unapproved-cidr",
+		Value: strconv.FormatBool(slices.ContainsFunc(ingress, func(r securityGroupRule) bool {
+			return r.isOpenToUnapprovedCidr(approvedCIDRs)
+		})),
+	})
+
+	ipv6Ports := openPortRanges(ingress, func(r securityGroupRule) bool {
+		return r.CidrIPv6 == internetCidrIPv6
+	})
+	props = append(props, &proto.Property{
+		Name:  "open-to-internet-ipv6",
+		Value: strconv.FormatBool(len(ipv6Ports) > 0),
+	})
+	if len(ipv6Ports) > 0 {
+		props = append(props, &proto.Property{
+			Name:  "open-to-internet-ipv6-ports",
+			Value: strings.Join(ipv6Ports, ","),
+		})
+	}
+
+	return props
+}
+
+// cidrPrefix parses the rule's IPv4 or IPv6 CIDR. It reports false for group references, prefix lists
+// and CIDRs that cannot be parsed.
+func (r securityGroupRule) cidrPrefix() (netip.Prefix, bool) {
+	cidr := r.CidrIPv4
+	if cidr == "" {
+		cidr = r.CidrIPv6
+	}
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	return prefix.Masked(), true
+}
+
+// covers reports whether r grants everything other does: the same protocol, a port range including
+// other's, and a CIDR containing other's.
+func (r securityGroupRule) covers(other securityGroupRule) bool {
+	if r.Direction != other.Direction || r.Protocol != other.Protocol || r.FromPort > other.FromPort || r.ToPort < other.ToPort {
+		return false
+	}
+	prefix, ok := r.cidrPrefix()
+	otherPrefix, otherOk := other.cidrPrefix()
+	return ok && otherOk && internal.PrefixContains(prefix, otherPrefix)
+}
+
+// redundantRuleCount counts the rules granting nothing beyond another rule of the list. Of rules
+// covering each other, i.e. duplicates, only the later ones are counted.
+func redundantRuleCount(rules []securityGroupRule) int {
+	count := 0
+	for i, rule := range rules {
+		for j, other := range rules {
+			if i != j && other.covers(rule) && (j < i || !rule.covers(other)) {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// redundancyProperties reports whether a group's ingress rules include rules that are fully covered by
+// a broader rule, e.g. a subnet alongside 0.0.0.0/0 on the same port, and how many.
+func redundancyProperties(group types.SecurityGroup) []*proto.Property {
+	count := redundantRuleCount(expandRules(ruleDirectionIngress, group.IpPermissions))
+	return []*proto.Property{
+		{
+			Name:  "has-redundant-rules",
+			Value: strconv.FormatBool(count > 0),
+		},
+		{
+			Name:  "redundant-rule-count",
+			Value: strconv.Itoa(count),
+		},
+	}
+}
+
+// danglingGroupReferences returns the sorted IDs of the groups referenced by group's rules that are not
+// in knownGroups, typically because they were deleted. Groups of another account, or reached through a
+// peering connection, cannot be listed in this region and are never reported.
+func danglingGroupReferences(group types.SecurityGroup, knownGroups map[string]bool) []string {
+	dangling := make([]string, 0)
+	for _, permission := range slices.Concat(group.IpPermissions, group.IpPermissionsEgress) {
+		for _, pair := range permission.UserIdGroupPairs {
+			groupID := aws.ToString(pair.GroupId)
+			if groupID == "" || knownGroups[groupID] || pair.VpcPeeringConnectionId != nil {
+				continue
+			}
+			if userID := aws.ToString(pair.UserId); userID != "" && userID != aws.ToString(group.OwnerId) {
+				continue
+			}
+			if !slices.Contains(dangling, groupID) {
+				dangling = append(dangling, groupID)
+			}
+		}
+	}
+	slices.Sort(dangling)
+	return dangling
+}
+
+// danglingReferenceProperties reports rules referencing groups that no longer exist. knownGroups is nil
+// when the region's groups could not all be listed, in which case nothing is reported.
+func danglingReferenceProperties(group types.SecurityGroup, knownGroups map[string]bool) []*proto.Property {
+	if knownGroups == nil {
+		return nil
+	}
+	dangling := danglingGroupReferences(group, knownGroups)
+	return []*proto.Property{
+		{
+			Name:  "has-dangling-group-reference",
+			Value: strconv.FormatBool(len(dangling) > 0),
+		},
+		{
+			Name:  "dangling-group-references",
+			Value: strings.Join(dangling, ","),
+		},
+	}
+}
+
+// isDefaultEgress reports whether the rule is the allow-all egress rule AWS adds to every new group:
+// all protocols to 0.0.0.0/0, plus to ::/0 in IPv6-enabled VPCs.
+func (r securityGroupRule) isDefaultEgress() bool {
+	return r.Direction == ruleDirectionEgress && r.Protocol == allProtocols && r.isOpenToInternet()
+}
+
+// isDefaultIPv4Egress reports whether the rule is the IPv4 allow-all egress rule, which AWS adds to
+// every new group regardless of the VPC's IPv6 configuration. Unlike isDefaultEgress, the ::/0 rule
+// of IPv6 VPCs does not count, so a group whose IPv4 default was removed is told apart.
+func (r securityGroupRule) isDefaultIPv4Egress() bool {
+	return r.Direction == ruleDirectionEgress && r.Protocol == allProtocols && r.CidrIPv4 == internetCidrIPv4
+}
+
+// egressExposureProperties computes internet exposure signals from a group's egress rules. The allow-all
+// rule AWS adds by default is reported apart from broad egress that was configured explicitly.
+func egressExposureProperties(group types.SecurityGroup) []*proto.Property {
+	egress := expandRules(ruleDirectionEgress, group.IpPermissionsEgress)
+
+	openPorts := openPortRanges(egress, securityGroupRule.isOpenToInternet)
+	props := []*proto.Property{
+		{
+			Name:  "egress-open-to-internet",
+			Value: strconv.FormatBool(len(openPorts) > 0),
+		},
+	}
+	if len(openPorts) > 0 {
+		props = append(props, &proto.Property{
+			Name:  "egress-open-to-internet-ports",
+			Value: strings.Join(openPorts, ","),
+		})
+	}
+
+	props = append(props,
+		&proto.Property{
+			Name:  "egress-default-allow-all",
+			Value: strconv.FormatBool(slices.ContainsFunc(egress, securityGroupRule.isDefaultEgress)),
+		},
+		&proto.Property{
+			Name:  "default-egress-rule-present",
+			Value: strconv.FormatBool(slices.ContainsFunc(egress, securityGroupRule.isDefaultIPv4Egress)),
+		},
+		&proto.Property{
+			Name: "egress-custom-open-to-internet",
+			Value: strconv.FormatBool(slices.ContainsFunc(egress, func(r securityGroupRule) bool {
+				return r.isOpenToInternet() && !r.isDefaultEgress()
+			})),
+		},
+	)
+
+	return props
+}
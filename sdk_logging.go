@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"github.com/aws/smithy-go/logging"
+	"github.com/hashicorp/go-hclog"
+	"regexp"
+)
+
+// sdkCredentialHeaders matches the request headers that carry credentials: the signature in
+// Authorization and the session token of temporary credentials.
+var sdkCredentialHeaders = regexp.MustCompile(`(?mi)^((?:Authorization|X-Amz-Security-Token):).*$`)
+
+// sdkLogger routes AWS SDK logs through the plugin logger, see aws_sdk_debug. SDK debug output is
+// logged at info level, as it has been asked for explicitly, with credential headers redacted.
+type sdkLogger struct {
+	logger hclog.Logger
+}
+
+func (s sdkLogger) Logf(classification logging.Classification, format string, v ...interface{}) {
+	message := sdkCredentialHeaders.ReplaceAllString(fmt.Sprintf(format, v...), "$1 [REDACTED]")
+	if classification == logging.Warn {
+		s.logger.Warn("AWS SDK", "message", message)
+		return
+	}
+	s.logger.Info("AWS SDK", "message", message)
+}
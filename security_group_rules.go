@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner/proto"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// loadSecurityGroupRules describes every security group rule of the region through the
+// DescribeSecurityGroupRules API, which unlike the rules embedded in a group carries rule IDs,
+// descriptions and tags. Rules are indexed by the ID of the group they belong to.
+func (l *CompliancePlugin) loadSecurityGroupRules(ctx context.Context, scan *regionScan) (map[string][]types.SecurityGroupRule, error) {
+	groupRules := map[string][]types.SecurityGroupRule{}
+	for rule, err := range getSecurityGroupRules(ctx, scan.client, &ec2.DescribeSecurityGroupRulesInput{}) {
+		if err != nil {
+			l.logger.Error("unable to get security group rules", "region", scan.region, "error", err)
+			return nil, err
+		}
+		groupID := aws.ToString(rule.GroupId)
+		groupRules[groupID] = append(groupRules[groupID], rule)
+	}
+	return groupRules, nil
+}
+
+// ruleTagProperties reports the tags of each of the group's rules keyed by rule ID, as
+// `rule/<rule-id>/tag/<key>`, so ownership tags can be checked on the rules that expose the group.
+// Every rule has a `rule/<rule-id>/tag-count`, zero when it is untagged, and
+// `rule/<rule-id>/open-to-internet`. Nothing is reported unless use_security_group_rules_api is enabled.
+func ruleTagProperties(group types.SecurityGroup, lookups ruleLookups) []*proto.Property {
+	apiRules := slices.Clone(lookups.groupRules[aws.ToString(group.GroupId)])
+	slices.SortFunc(apiRules, func(a, b types.SecurityGroupRule) int {
+		return strings.Compare(aws.ToString(a.SecurityGroupRuleId), aws.ToString(b.SecurityGroupRuleId))
+	})
+	props := make([]*proto.Property, 0)
+	for _, apiRule := range apiRules {
+		prefix := "rule/" + aws.ToString(apiRule.SecurityGroupRuleId)
+		openToInternet := aws.ToString(apiRule.CidrIpv4) == internetCidrIPv4 || aws.ToString(apiRule.CidrIpv6) == internetCidrIPv6
+		props = append(props,
+			&proto.Property{Name: prefix + "/tag-count", Value: strconv.Itoa(len(apiRule.Tags))},
+			&proto.Property{Name: prefix + "/open-to-internet", Value: strconv.FormatBool(openToInternet)},
+		)
+		for _, tag := range apiRule.Tags {
+			props = append(props, &proto.Property{Name: prefix + "/tag/" + aws.ToString(tag.Key), Value: aws.ToString(tag.Value)})
+		}
+	}
+	return props
+}
+
+// annotate copies the ID, description and tags of the matching rule returned by the
+// DescribeSecurityGroupRules API onto r. r is returned unchanged when no rule matches.
+func (r securityGroupRule) annotate(apiRules []types.SecurityGroupRule) securityGroupRule {
+	for _, apiRule := range apiRules {
+		if r.matches(apiRule) {
+			r.RuleID = aws.ToString(apiRule.SecurityGroupRuleId)
+			r.RuleTags = apiRule.Tags
+			if r.Description == "" {
+				r.Description = aws.ToString(apiRule.Description)
+			}
+			return r
+		}
+	}
+	return r
+}
+
+// matches reports whether apiRule is the DescribeSecurityGroupRules representation of r: the same
+// direction, protocol, ports and single source or destination.
+func (r securityGroupRule) matches(apiRule types.SecurityGroupRule) bool {
+	if aws.ToBool(apiRule.IsEgress) != (r.Direction == ruleDirectionEgress) {
+		return false
+	}
+
+	protocol := aws.ToString(apiRule.IpProtocol)
+	if protocol == "-1" {
+		protocol = allProtocols
+	}
+	if protocol != r.Protocol {
+		return false
+	}
+	// All-protocol rules are reported with ports of -1 by the rules API and normalised here.
+	if protocol != allProtocols && (aws.ToInt32(apiRule.FromPort) != r.FromPort || aws.ToInt32(apiRule.ToPort) != r.ToPort) {
+		return false
+	}
+
+	referencedGroupID := ""
+	if apiRule.ReferencedGroupInfo != nil {
+		referencedGroupID = aws.ToString(apiRule.ReferencedGroupInfo.GroupId)
+	}
+	return aws.ToString(apiRule.CidrIpv4) == r.CidrIPv4 &&
+		aws.ToString(apiRule.CidrIpv6) == r.CidrIPv6 &&
+		aws.ToString(apiRule.PrefixListId) == r.PrefixListID &&
+		referencedGroupID == r.ReferencedGroupID
+}
+
+func getSecurityGroupRules(ctx context.Context, client NetworkingAPI, input *ec2.DescribeSecurityGroupRulesInput) iter.Seq2[types.SecurityGroupRule, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeSecurityGroupRulesOutput, *ec2.Options] {
+		return ec2.NewDescribeSecurityGroupRulesPaginator(client, input)
+	}, func(page *ec2.DescribeSecurityGroupRulesOutput) []types.SecurityGroupRule {
+		return page.SecurityGroupRules
+	})
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"iter"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// securityGroupInput is the policy input for a security group. The group's own fields are embedded so
+// policies see the same document as the EC2 API returns, with resolved references alongside.
+type securityGroupInput struct {
+	types.SecurityGroup
+	// PrefixListCidrs maps each prefix list referenced by the group's rules to the CIDRs it contains.
+	PrefixListCidrs map[string][]string
+	// Rules are the group's rules as returned by the DescribeSecurityGroupRules API, with their IDs and
+	// tags. They are only present when use_security_group_rules_api is enabled.
+	Rules []types.SecurityGroupRule `json:",omitempty"`
+	// RAMShares are the principals the group is shared with through AWS Resource Access Manager.
+	RAMShares []ramShare `json:",omitempty"`
+	// Flat is the group's normalized representation, only present when flat_evidence is enabled.
+	Flat *flatSecurityGroup `json:",omitempty"`
+}
+
+func newSecurityGroupInput(group types.SecurityGroup, lookups ruleLookups) securityGroupInput {
+	input := securityGroupInput{
+		SecurityGroup:   group,
+		PrefixListCidrs: map[string][]string{},
+		Rules:           lookups.groupRules[aws.ToString(group.GroupId)],
+	}
+	for _, permission := range slices.Concat(group.IpPermissions, group.IpPermissionsEgress) {
+		for _, prefixList := range permission.PrefixListIds {
+			id := aws.ToString(prefixList.PrefixListId)
+			input.PrefixListCidrs[id] = lookups.prefixListCIDRs[id]
+		}
+	}
+	return input
+}
+
+var securityGroupComponent = &proto.Component{
+	Identifier:  "common-components/amazon-security-group",
+	Type:        "service",
+	Title:       "Amazon Security Groups",
+	Description: "Amazon Security Groups act as virtual firewalls for AWS resources such as EC2 instances and RDS databases. They control inbound and outbound traffic at the instance level using rule-based configurations tied to ports, protocols, and CIDR ranges. Security Groups are stateful and can reference other groups to enforce dynamic trust boundaries within a VPC.",
+	Purpose:     "To enforce network segmentation and access control policies at the resource level, providing a configurable and auditable security boundary for cloud-based assets in support of least privilege and Zero Trust architectures.",
+}
+
+func (l *CompliancePlugin) evalSecurityGroups(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	input := &ec2.DescribeSecurityGroupsInput{
+		GroupIds: l.config.GroupIDs,
+		Filters:  slices.Concat(l.vpcFilters(), l.tagFilterSet()),
+	}
+
+	if l.config.Discovery == internal.DiscoveryResourceGroups {
+		groupIDs, err := l.discoverSecurityGroups(ctx, scan)
+		if err != nil {
+			return err
+		}
+		// An empty GroupIds would describe every group of the region.
+		if len(groupIDs) == 0 {
+			return l.reportNoResources(ctx, scan, "security-group", "security groups", collectionActivities("security group", "DescribeSecurityGroups"), apiHelper)
+		}
+		input.GroupIds = groupIDs
+	}
+
+	// Tags used to select the groups are recorded so a reviewer can see why a group is in scope.
+	filterLabels := map[string]string{}
+	for key, value := range l.config.TagFilters {
+		filterLabels["tag/"+key] = value
+	}
+
+	// Groups are collected up front so that rules referencing other groups can be resolved to their
+	// names without further API calls.
+	// A failed page ends pagination, but groups from earlier pages are still evaluated so their evidence
+	// is not lost.
+	groups := make([]types.SecurityGroup, 0)
+	knownGroups := map[string]bool{}
+	for group, err := range getSecurityGroups(ctx, scan.client, input) {
+		if err != nil {
+			// EC2 fails the whole request when any requested ID is unknown in the region.
+			var apiErr smithy.APIError
+			if errors.As(err, &apiErr) && apiErr.ErrorCode() == "InvalidGroup.NotFound" {
+				err = fmt.Errorf("security groups requested by group_ids or discovered through resource groups were not found: %w", err)
+			}
+			l.logger.Error("unable to get security group, evaluating groups collected so far", "region", scan.region, "collected", len(groups), "error", err)
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+			knownGroups = nil
+			continue
+		}
+		if knownGroups != nil {
+			knownGroups[aws.ToString(group.GroupId)] = true
+		}
+		if scan.excludesVpc(aws.ToString(group.VpcId)) {
+			continue
+		}
+		groups = append(groups, group)
+	}
+
+	lookups := ruleLookups{
+		groupNames:      map[string]string{},
+		prefixListCIDRs: scan.prefixListCIDRs,
+	}
+	for _, group := range groups {
+		lookups.groupNames[aws.ToString(group.GroupId)] = aws.ToString(group.GroupName)
+	}
+	// Without the rules API, rules are still reported, only without their IDs and tags.
+	if l.config.UseSecurityGroupRulesAPI {
+		groupRules, err := l.loadSecurityGroupRules(ctx, scan)
+		if err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+		lookups.groupRules = groupRules
+	}
+
+	// A scoped request only returns some of the region's groups, so dangling references are checked
+	// against a separate listing of every group.
+	if knownGroups != nil && (len(input.GroupIds) > 0 || len(input.Filters) > 0) {
+		knownGroups = l.loadKnownGroups(ctx, scan)
+	}
+
+	// Attachments are only known when the interfaces could be described; otherwise in-use is omitted
+	// rather than reported as false.
+	groupInterfaces, err := l.loadGroupInterfaces(ctx, scan)
+	if err != nil {
+		accumulatedErrors = errors.Join(accumulatedErrors, err)
+	}
+
+	// Sharing is omitted when RAM could not be queried, like in-use for interfaces.
+	groupShares, err := l.loadGroupShares(ctx, scan)
+	if err != nil {
+		accumulatedErrors = errors.Join(accumulatedErrors, err)
+	}
+
+	// Run policy checks
+	skipped := 0
+	for _, group := range groups {
+		if err := ctx.Err(); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+			break
+		}
+		// Groups are skipped only now, so rules referencing them still resolve.
+		if l.skipsSecurityGroup(group) {
+			skipped++
+			continue
+		}
+
+		data := newSecurityGroupInput(group, lookups)
+		data.RAMShares = groupShares[aws.ToString(group.GroupId)]
+		if l.config.FlatEvidence {
+			data.Flat = newFlatSecurityGroup(group)
+		}
+		flatProps, err := flatProperties(data.Flat)
+		if err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+			continue
+		}
+
+		labels := internal.MergeMaps(scan.labels, filterLabels, l.tagLabels(group.Tags), map[string]string{
+			"type":       "security-group",
+			"group-id":   aws.ToString(group.GroupId),
+			"_vpc-id":    aws.ToString(group.VpcId),
+			"is-default": strconv.FormatBool(isDefaultSecurityGroup(group)),
+		})
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-security-group/%s", aws.ToString(group.GroupId)),
+			Type:       "firewall",
+			Title:      fmt.Sprintf("Amazon Security Group [%s]", aws.ToString(group.GroupId)),
+			Props: slices.Concat([]*proto.Property{
+				{
+					Name:  "group-id",
+					Value: aws.ToString(group.GroupId),
+				},
+				{
+					Name:  "group-name",
+					Value: aws.ToString(group.GroupName),
+				},
+				{
+					Name:  "vpc-id",
+					Value: aws.ToString(group.VpcId),
+				},
+				{
+					Name:  "is-default",
+					Value: strconv.FormatBool(isDefaultSecurityGroup(group)),
+				},
+			}, tagProperties(group.Tags), ruleCountProperties(group), ruleProperties(group, lookups), ruleTagProperties(group, lookups), exposureProperties(group, l.config.SensitivePorts, l.config.ApprovedCIDRs), severityProperties(group, l.config.SensitivePorts, l.config.SeverityLevels), egressExposureProperties(group), redundancyProperties(group), danglingReferenceProperties(group, knownGroups), usageProperties(group, groupInterfaces), l.privateIPProperties(group, groupInterfaces), ramSharingProperties(group, groupShares), vpcProperties(scan, aws.ToString(group.VpcId)), flatProps),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, securityGroupComponent, inventory, collectionActivities("security group", "DescribeSecurityGroups"), data); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	if skipped > 0 {
+		l.logger.Info("Skipped security groups excluded by configuration", "region", scan.region, "skipped", skipped)
+		scan.summary.recordFiltered("security-group", skipped)
+	}
+
+	if len(groups) == skipped && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "security-group", "security groups", collectionActivities("security group", "DescribeSecurityGroups"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+// skipsSecurityGroup reports whether configuration leaves group out of evaluation: it has no ingress
+// rules and require_ingress_rules is set, or it is a default group and skip_default_group is set. A
+// group requested by ID through group_ids is never skipped as a default group.
+func (l *CompliancePlugin) skipsSecurityGroup(group types.SecurityGroup) bool {
+	if l.config.RequireIngressRules && len(group.IpPermissions) == 0 {
+		return true
+	}
+	return l.config.SkipDefaultGroup && isDefaultSecurityGroup(group) && !slices.Contains(l.config.GroupIDs, aws.ToString(group.GroupId))
+}
+
+// loadKnownGroups returns the IDs of every security group in the region, or nil when they could not
+// be listed. A failure only loses the dangling reference check, so it is logged rather than returned.
+func (l *CompliancePlugin) loadKnownGroups(ctx context.Context, scan *regionScan) map[string]bool {
+	knownGroups := map[string]bool{}
+	for group, err := range getSecurityGroups(ctx, scan.client, &ec2.DescribeSecurityGroupsInput{}) {
+		if err != nil {
+			l.logger.Warn("unable to list security groups, dangling group references are not reported", "region", scan.region, "error", err)
+			return nil
+		}
+		knownGroups[aws.ToString(group.GroupId)] = true
+	}
+	return knownGroups
+}
+
+// isDefaultSecurityGroup reports whether group is the default security group of its VPC. AWS names it
+// exactly "default" and does not allow it to be renamed, nor any other group to take that name, so an
+// exact, case-sensitive match is sufficient. A missing name is never treated as default.
+func isDefaultSecurityGroup(group types.SecurityGroup) bool {
+	return group.GroupName != nil && *group.GroupName == "default"
+}
+
+// usageProperties reports whether any network interface uses the group, and the kinds of resource the
+// interfaces using it belong to. groupInterfaces is nil when
+// interfaces could not be described, in which case nothing is reported.
+func usageProperties(group types.SecurityGroup, groupInterfaces map[string][]types.NetworkInterface) []*proto.Property {
+	if groupInterfaces == nil {
+		return nil
+	}
+	interfaces := groupInterfaces[aws.ToString(group.GroupId)]
+	resourceTypes := make([]string, 0)
+	for _, eni := range interfaces {
+		if resourceType := interfaceResourceType(eni); !slices.Contains(resourceTypes, resourceType) {
+			resourceTypes = append(resourceTypes, resourceType)
+		}
+	}
+	slices.Sort(resourceTypes)
+	return []*proto.Property{
+		{
+			Name:  "in-use",
+			Value: strconv.FormatBool(len(interfaces) > 0),
+		},
+		{
+			Name:  "attached-resource-types",
+			Value: strings.Join(resourceTypes, ","),
+		},
+	}
+}
+
+// maxAssociatedPrivateIPs bounds the addresses listed in associated-private-ips, so groups attached to
+// many interfaces do not produce giant properties.
+const maxAssociatedPrivateIPs = 50
+
+// privateIPProperties lists the private IPv4 addresses of the network interfaces using the group, when
+// report_private_ips is set. At most maxAssociatedPrivateIPs are listed, in order, and
+// associated-private-ips-truncated tells when more were left out. Nothing is reported when interfaces
+// could not be described.
+func (l *CompliancePlugin) privateIPProperties(group types.SecurityGroup, groupInterfaces map[string][]types.NetworkInterface) []*proto.Property {
+	if !l.config.ReportPrivateIPs || groupInterfaces == nil {
+		return nil
+	}
+	addresses := make([]string, 0)
+	for _, eni := range groupInterfaces[aws.ToString(group.GroupId)] {
+		for _, address := range eni.PrivateIpAddresses {
+			if ip := aws.ToString(address.PrivateIpAddress); ip != "" && !slices.Contains(addresses, ip) {
+				addresses = append(addresses, ip)
+			}
+		}
+	}
+	slices.SortFunc(addresses, func(a, b string) int {
+		addrA, errA := netip.ParseAddr(a)
+		addrB, errB := netip.ParseAddr(b)
+		if errA != nil || errB != nil {
+			return strings.Compare(a, b)
+		}
+		return addrA.Compare(addrB)
+	})
+	truncated := len(addresses) > maxAssociatedPrivateIPs
+	if truncated {
+		addresses = addresses[:maxAssociatedPrivateIPs]
+	}
+	return []*proto.Property{
+		{
+			Name:  "associated-private-ips",
+			Value: strings.Join(addresses, ","),
+		},
+		{
+			Name:  "associated-private-ips-truncated",
+			Value: strconv.FormatBool(truncated),
+		},
+	}
+}
+
+func getSecurityGroups(ctx context.Context, client NetworkingAPI, input *ec2.DescribeSecurityGroupsInput) iter.Seq2[types.SecurityGroup, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeSecurityGroupsOutput, *ec2.Options] {
+		return ec2.NewDescribeSecurityGroupsPaginator(client, input)
+	}, func(page *ec2.DescribeSecurityGroupsOutput) []types.SecurityGroup {
+		return page.SecurityGroups
+	})
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal/awsmock"
+	"slices"
+	"testing"
+)
+
+func TestGetSecurityGroups(t *testing.T) {
+	errDescribe := errors.New("describe failed")
+	tests := []struct {
+		name    string
+		client  *awsmock.EC2
+		want    []string
+		wantErr error
+	}{
+		{
+			name:   "no groups",
+			client: &awsmock.EC2{},
+		},
+		{
+			name: "single page",
+			client: &awsmock.EC2{SecurityGroups: [][]types.SecurityGroup{
+				{securityGroup("sg-1", "vpc-1"), securityGroup("sg-2", "vpc-1")},
+			}},
+			want: []string{"sg-1", "sg-2"},
+		},
+		{
+			name: "multiple pages",
+			client: &awsmock.EC2{SecurityGroups: [][]types.SecurityGroup{
+				{securityGroup("sg-1", "vpc-1")},
+				{securityGroup("sg-2", "vpc-1"), securityGroup("sg-3", "vpc-2")},
+				{securityGroup("sg-4", "vpc-2")},
+			}},
+			want: []string{"sg-1", "sg-2", "sg-3", "sg-4"},
+		},
+		{
+			name: "error on every page",
+			client: &awsmock.EC2{
+				SecurityGroups: [][]types.SecurityGroup{{securityGroup("sg-1", "vpc-1")}},
+				Errors:         map[string]error{"DescribeSecurityGroups": errDescribe},
+			},
+			wantErr: errDescribe,
+		},
+		{
+			name: "error on a later page",
+			client: &awsmock.EC2{
+				SecurityGroups: [][]types.SecurityGroup{
+					{securityGroup("sg-1", "vpc-1")},
+					{securityGroup("sg-2", "vpc-1")},
+					{securityGroup("sg-3", "vpc-1")},
+				},
+				Errors: map[string]error{"DescribeSecurityGroups/1": errDescribe},
+			},
+			want:    []string{"sg-1"},
+			wantErr: errDescribe,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []string
+			var errs []error
+			for group, err := range getSecurityGroups(context.Background(), test.client, &ec2.DescribeSecurityGroupsInput{}) {
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				got = append(got, aws.ToString(group.GroupId))
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("groups = %v, want %v", got, test.want)
+			}
+			if test.wantErr == nil && len(errs) > 0 {
+				t.Errorf("errors = %v, want none", errs)
+			}
+			if test.wantErr != nil && (len(errs) != 1 || !errors.Is(errs[0], test.wantErr)) {
+				t.Errorf("errors = %v, want %v once", errs, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"net/netip"
+	"slices"
+)
+
+// Exposure signals a security group's severity is derived from, from the most to the least severe.
+// The first signal a group shows decides its severity.
+const (
+	exposureAllTraffic    = "all-traffic"
+	exposureSensitivePort = "sensitive-port"
+	exposurePublicIngress = "public-ingress"
+	exposureNone          = "none"
+)
+
+// severityLevels are the severities a signal can be assigned through the severity_levels config key.
+var severityLevels = []string{"critical", "high", "medium", "low"}
+
+// defaultSeverities maps each exposure signal to its severity unless overridden by severity_levels.
+var defaultSeverities = map[string]string{
+	exposureAllTraffic:    "critical",
+	exposureSensitivePort: "high",
+	exposurePublicIngress: "medium",
+	exposureNone:          "low",
+}
+
+// exposureSignal returns the most severe exposure signal shown by a group's ingress rules: all
+// protocols open to the internet, a sensitive port open to the internet, or any public CIDR granted.
+func exposureSignal(group types.SecurityGroup, sensitivePorts []int32) string {
+	ingress := expandRules(ruleDirectionIngress, group.IpPermissions)
+	switch {
+	case slices.ContainsFunc(ingress, func(r securityGroupRule) bool {
+		return r.Protocol == allProtocols && r.isOpenToInternet()
+	}):
+		return exposureAllTraffic
+	case slices.ContainsFunc(ingress, func(r securityGroupRule) bool {
+		return r.isOpenToInternet() && slices.ContainsFunc(sensitivePorts, r.coversPort)
+	}):
+		return exposureSensitivePort
+	case slices.ContainsFunc(ingress, func(r securityGroupRule) bool {
+		// Public CIDRs are reported by isOpenToUnapprovedCidr when no CIDR is approved.
+		return r.isOpenToUnapprovedCidr([]netip.Prefix{})
+	}):
+		return exposurePublicIngress
+	}
+	return exposureNone
+}
+
+// severityProperties reports a group's `severity`, as mapped from its exposure signal by severities,
+// along with the `severity-reason` signal itself. Severity is a triage aid only; policies remain the
+// source of truth for compliance.
+func severityProperties(group types.SecurityGroup, sensitivePorts []int32, severities map[string]string) []*proto.Property {
+	signal := exposureSignal(group, sensitivePorts)
+	severity := internal.MergeMaps(defaultSeverities, severities)[signal]
+	return []*proto.Property{
+		{
+			Name:  "severity",
+			Value: severity,
+		},
+		{
+			Name:  "severity-reason",
+			Value: signal,
+		},
+	}
+}
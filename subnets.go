@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"iter"
+	"slices"
+	"strconv"
+)
+
+var subnetComponent = &proto.Component{
+	Identifier:  "common-components/amazon-vpc-subnet",
+	Type:        "service",
+	Title:       "Amazon VPC Subnets",
+	Description: "Amazon VPC subnets are ranges of IP addresses within a VPC, each bound to a single Availability Zone. Subnet settings such as automatic public IP assignment determine whether resources launched into them are directly reachable from the internet.",
+	Purpose:     "To partition a VPC into public and private network tiers, enforcing which resources may receive public addresses in support of network segmentation and least exposure.",
+}
+
+func (l *CompliancePlugin) evalSubnets(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	input := &ec2.DescribeSubnetsInput{
+		Filters: l.vpcFilters(),
+	}
+
+	found := 0
+	for subnet, err := range getSubnets(ctx, scan.client, input) {
+		if err != nil {
+			l.logger.Error("unable to get subnet", "region", scan.region, "error", err)
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+			continue
+		}
+		if scan.excludesVpc(aws.ToString(subnet.VpcId)) {
+			continue
+		}
+		found++
+
+		subnetID := aws.ToString(subnet.SubnetId)
+		mapPublicIPOnLaunch := strconv.FormatBool(aws.ToBool(subnet.MapPublicIpOnLaunch))
+
+		labels := internal.MergeMaps(scan.labels, l.tagLabels(subnet.Tags), map[string]string{
+			"type":              "subnet",
+			"subnet-id":         subnetID,
+			"availability-zone": aws.ToString(subnet.AvailabilityZone),
+			"_vpc-id":           aws.ToString(subnet.VpcId),
+		})
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-subnet/%s", subnetID),
+			Type:       "network",
+			Title:      fmt.Sprintf("Amazon VPC Subnet [%s]", subnetID),
+			Props: slices.Concat([]*proto.Property{
+				{
+					Name:  "subnet-id",
+					Value: subnetID,
+				},
+				{
+					Name:  "vpc-id",
+					Value: aws.ToString(subnet.VpcId),
+				},
+				{
+					Name:  "cidr-block",
+					Value: aws.ToString(subnet.CidrBlock),
+				},
+				{
+					Name:  "availability-zone",
+					Value: aws.ToString(subnet.AvailabilityZone),
+				},
+				{
+					Name:  "map-public-ip-on-launch",
+					Value: mapPublicIPOnLaunch,
+				},
+			}, tagProperties(subnet.Tags)),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, subnetComponent, inventory, collectionActivities("subnet", "DescribeSubnets"), subnet); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	if found == 0 && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "subnet", "subnets", collectionActivities("subnet", "DescribeSubnets"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+func getSubnets(ctx context.Context, client NetworkingAPI, input *ec2.DescribeSubnetsInput) iter.Seq2[types.Subnet, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeSubnetsOutput, *ec2.Options] {
+		return ec2.NewDescribeSubnetsPaginator(client, input)
+	}, func(page *ec2.DescribeSubnetsOutput) []types.Subnet {
+		return page.Subnets
+	})
+}
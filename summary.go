@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// runSummary aggregates what an evaluation covered across every region, reported as a single evidence
+// record at the end of the run.
+type runSummary struct {
+	mu                sync.Mutex
+	started           time.Time
+	resources         map[string]int
+	policyEvaluations int
+
+	// maxResources caps the resources evaluated, see max_resources, and truncated records that the cap
+	// was reached and resources were left out.
+	maxResources int
+	truncated    bool
+
+	// filtered counts the resources of each type collected but left out by configuration, such as
+	// require_ingress_rules.
+	filtered map[string]int
+
+	// passes counts the collection passes run across all regions, failedPasses those that returned an
+	// error and skippedPasses those skipped for lack of permissions.
+	passes        int
+	failedPasses  int
+	skippedPasses int
+}
+
+func newRunSummary(maxResources int) *runSummary {
+	return &runSummary{
+		started:      time.Now(),
+		resources:    map[string]int{},
+		filtered:     map[string]int{},
+		maxResources: maxResources,
+	}
+}
+
+// recordResource counts a resource of resourceType about to be evaluated against policies policies.
+// It returns false, counting nothing, once maxResources resources have been recorded, in which case the
+// resource must not be evaluated.
+func (s *runSummary) recordResource(resourceType string, policies int) bool {
+	if s == nil {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.maxResources > 0 && s.total() >= s.maxResources {
+		s.truncated = true
+		return false
+	}
+	s.resources[resourceType]++
+	s.policyEvaluations += policies
+	return true
+}
+
+// total returns the number of resources recorded. The caller holds s.mu.
+func (s *runSummary) total() int {
+	total := 0
+	for _, count := range s.resources {
+		total += count
+	}
+	return total
+}
+
+// capReached reports whether maxResources resources have been recorded, so no further resource will be
+// evaluated.
+func (s *runSummary) capReached() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.maxResources > 0 && s.total() >= s.maxResources
+}
+
+// wasTruncated reports whether resources were left out because maxResources was reached.
+func (s *runSummary) wasTruncated() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.truncated
+}
+
+// recordFiltered counts count resources of resourceType that were left out of evaluation by
+// configuration.
+func (s *runSummary) recordFiltered(resourceType string, count int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.filtered[resourceType] += count
+}
+
+// recordPass counts a collection pass, which failed when err is not nil.
+func (s *runSummary) recordPass(err error, skipped bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.passes++
+	if err != nil {
+		s.failedPasses++
+	}
+	if skipped {
+		s.skippedPasses++
+	}
+}
+
+// allPassesFailed reports whether passes were run and every one of them failed.
+func (s *runSummary) allPassesFailed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.passes > 0 && s.failedPasses == s.passes
+}
+
+// nothingCollected reports whether no pass ran, or every pass that ran failed.
+func (s *runSummary) nothingCollected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.failedPasses == s.passes
+}
+
+// reportRunSummary sends the run summary evidence. It is sent whatever the outcome of the run, so
+// operators always get a digest of what was covered.
+func (l *CompliancePlugin) reportRunSummary(ctx context.Context, summary *runSummary, regions []string, policies int, runErr error, apiHelper runner.ApiHelper) error {
+	summary.mu.Lock()
+	defer summary.mu.Unlock()
+
+	resolvedVersion, _ := pluginVersion()
+	props := []*proto.Property{
+		{
+			Name:  "regions",
+			Value: strings.Join(regions, ","),
+		},
+		{
+			Name:  "policies",
+			Value: strconv.Itoa(policies),
+		},
+		{
+			Name:  "policy-evaluations",
+			Value: strconv.Itoa(summary.policyEvaluations),
+		},
+		{
+			Name:  "passes-skipped",
+			Value: strconv.Itoa(summary.skippedPasses),
+		},
+		{
+			Name:  "errors",
+			Value: strconv.Itoa(countErrors(runErr)),
+		},
+		{
+			Name:  "elapsed-seconds",
+			Value: strconv.FormatFloat(time.Since(summary.started).Seconds(), 'f', 1, 64),
+		},
+		{
+			Name:  "plugin-version",
+			Value: resolvedVersion,
+		},
+		{
+			Name:  "config-hash",
+			Value: l.configHash,
+		},
+	}
+	total := 0
+	for _, resourceType := range slices.Sorted(maps.Keys(summary.resources)) {
+		total += summary.resources[resourceType]
+		props = append(props, &proto.Property{
+			Name:  "resources/" + resourceType,
+			Value: strconv.Itoa(summary.resources[resourceType]),
+		})
+	}
+	props = append(props, &proto.Property{
+		Name:  "resources-scanned",
+		Value: strconv.Itoa(total),
+	}, &proto.Property{
+		Name:  "truncated",
+		Value: strconv.FormatBool(summary.truncated),
+	})
+	for _, resourceType := range slices.Sorted(maps.Keys(summary.filtered)) {
+		props = append(props, &proto.Property{
+			Name:  "resources-filtered/" + resourceType,
+			Value: strconv.Itoa(summary.filtered[resourceType]),
+		})
+	}
+
+	description := fmt.Sprintf("The plugin evaluated %d resources across %d regions.", total, len(regions))
+	if summary.truncated {
+		description += fmt.Sprintf(" The evaluation was capped at max_resources %d, so further resources were not evaluated.", summary.maxResources)
+	}
+	evidence, err := l.newPluginEvidence(
+		"AWS networking evaluation summary",
+		description,
+		map[string]string{
+			"provider": "aws",
+			"type":     "run-summary",
+		},
+		[]*proto.Activity{
+			{
+				Title:       "Summarise the evaluation",
+				Description: "Aggregate the resources, policies and errors of every region scanned in this run.",
+			},
+		},
+		props,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err = apiHelper.CreateEvidence(ctx, []*proto.Evidence{evidence}); err != nil {
+		l.logger.Error("Failed to send evidences", "type", "run-summary", "error", err)
+		return err
+	}
+	return nil
+}
+
+// countErrors returns the number of individual errors joined into err.
+func countErrors(err error) int {
+	if err == nil {
+		return 0
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		count := 0
+		for _, inner := range joined.Unwrap() {
+			count += countErrors(inner)
+		}
+		return count
+	}
+	return 1
+}
+
+// configFingerprint returns a stable SHA-256 of the plugin configuration, so runs can be correlated
+// with the configuration they used. Sensitive and operational keys are removed by the caller, see
+// internal.OmitSensitiveConfig and internal.OmitOperationalConfig.
+func configFingerprint(config map[string]string) string {
+	hash := sha256.New()
+	for _, key := range slices.Sorted(maps.Keys(config)) {
+		fmt.Fprintf(hash, "%s=%s\n", key, config[key])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
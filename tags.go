@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner/proto"
+)
+
+// tagProperties renders resource tags as `tag/<key>` properties. Resources without tags produce no
+// properties.
+func tagProperties(tags []types.Tag) []*proto.Property {
+	props := make([]*proto.Property, 0, len(tags))
+	for _, tag := range tags {
+		if aws.ToString(tag.Key) == "" {
+			continue
+		}
+		props = append(props, &proto.Property{
+			Name:  "tag/" + aws.ToString(tag.Key),
+			Value: aws.ToString(tag.Value),
+		})
+	}
+	return props
+}
+
+// tagLabels promotes the tags listed in the label_tags config key to `tag/<key>` labels.
+func (l *CompliancePlugin) tagLabels(tags []types.Tag) map[string]string {
+	labels := map[string]string{}
+	for _, tag := range tags {
+		for _, key := range l.config.LabelTags {
+			if aws.ToString(tag.Key) == key {
+				labels["tag/"+key] = aws.ToString(tag.Value)
+			}
+		}
+	}
+	return labels
+}
@@ -0,0 +1,3 @@
+package compliance_framework.test_policy
+
+title := "Test policy"
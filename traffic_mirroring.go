@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"iter"
+	"slices"
+	"strconv"
+)
+
+var trafficMirrorComponent = &proto.Component{
+	Identifier:  "common-components/amazon-vpc-traffic-mirroring",
+	Type:        "service",
+	Title:       "Amazon VPC Traffic Mirroring",
+	Description: "Amazon VPC Traffic Mirroring copies the traffic of elastic network interfaces to a target, such as another interface or a load balancer in front of monitoring appliances, for out-of-band inspection.",
+	Purpose:     "To give intrusion detection and network forensics tooling a copy of the traffic of sensitive workloads, without placing appliances in the traffic path.",
+}
+
+// trafficMirrorSession is the policy input for a traffic mirror session: the session as returned by the
+// EC2 API, with the target it mirrors to alongside. Target is nil when the target could not be found,
+// e.g. because it belongs to another account.
+type trafficMirrorSession struct {
+	types.TrafficMirrorSession
+	Target *types.TrafficMirrorTarget
+}
+
+func (l *CompliancePlugin) evalTrafficMirrorSessions(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	// Sessions are not evaluated without their targets, as a missing target would read as a session
+	// mirroring to nowhere.
+	targets := map[string]types.TrafficMirrorTarget{}
+	for target, err := range getTrafficMirrorTargets(ctx, scan.client, &ec2.DescribeTrafficMirrorTargetsInput{}) {
+		if err != nil {
+			l.logger.Error("unable to get traffic mirror targets", "region", scan.region, "error", err)
+			return err
+		}
+		targets[aws.ToString(target.TrafficMirrorTargetId)] = target
+	}
+
+	found := 0
+	for session, err := range getTrafficMirrorSessions(ctx, scan.client, &ec2.DescribeTrafficMirrorSessionsInput{}) {
+		if err != nil {
+			l.logger.Error("unable to get traffic mirror session", "region", scan.region, "error", err)
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+			continue
+		}
+		found++
+
+		sessionID := aws.ToString(session.TrafficMirrorSessionId)
+		data := trafficMirrorSession{TrafficMirrorSession: session}
+		if target, ok := targets[aws.ToString(session.TrafficMirrorTargetId)]; ok {
+			data.Target = &target
+		}
+
+		labels := internal.MergeMaps(scan.labels, l.tagLabels(session.Tags), map[string]string{
+			"type":                      "traffic-mirror-session",
+			"traffic-mirror-session-id": sessionID,
+			"network-interface-id":      aws.ToString(session.NetworkInterfaceId),
+		})
+
+		props := []*proto.Property{
+			{
+				Name:  "traffic-mirror-session-id",
+				Value: sessionID,
+			},
+			{
+				Name:  "network-interface-id",
+				Value: aws.ToString(session.NetworkInterfaceId),
+			},
+			{
+				Name:  "traffic-mirror-target-id",
+				Value: aws.ToString(session.TrafficMirrorTargetId),
+			},
+			{
+				Name:  "traffic-mirror-filter-id",
+				Value: aws.ToString(session.TrafficMirrorFilterId),
+			},
+			{
+				Name:  "session-number",
+				Value: strconv.Itoa(int(aws.ToInt32(session.SessionNumber))),
+			},
+		}
+		if data.Target != nil {
+			props = append(props, trafficMirrorTargetProperties(*data.Target)...)
+		}
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-traffic-mirror-session/%s", sessionID),
+			Type:       "network",
+			Title:      fmt.Sprintf("Amazon VPC Traffic Mirror Session [%s]", sessionID),
+			Props:      slices.Concat(props, tagProperties(session.Tags)),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, trafficMirrorComponent, inventory, collectionActivities("traffic mirror session", "DescribeTrafficMirrorSessions"), data); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	if found == 0 && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "traffic-mirror-session", "traffic mirror sessions", collectionActivities("traffic mirror session", "DescribeTrafficMirrorSessions"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+// trafficMirrorTargetProperties describes where a session's traffic is sent. Only the field matching
+// the target type is set by AWS, the others are reported empty.
+func trafficMirrorTargetProperties(target types.TrafficMirrorTarget) []*proto.Property {
+	return []*proto.Property{
+		{
+			Name:  "target-type",
+			Value: string(target.Type),
+		},
+		{
+			Name:  "target-network-interface-id",
+			Value: aws.ToString(target.NetworkInterfaceId),
+		},
+		{
+			Name:  "target-network-load-balancer-arn",
+			Value: aws.ToString(target.NetworkLoadBalancerArn),
+		},
+		{
+			Name:  "target-gateway-load-balancer-endpoint-id",
+			Value: aws.ToString(target.GatewayLoadBalancerEndpointId),
+		},
+	}
+}
+
+func getTrafficMirrorTargets(ctx context.Context, client NetworkingAPI, input *ec2.DescribeTrafficMirrorTargetsInput) iter.Seq2[types.TrafficMirrorTarget, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeTrafficMirrorTargetsOutput, *ec2.Options] {
+		return ec2.NewDescribeTrafficMirrorTargetsPaginator(client, input)
+	}, func(page *ec2.DescribeTrafficMirrorTargetsOutput) []types.TrafficMirrorTarget {
+		return page.TrafficMirrorTargets
+	})
+}
+
+func getTrafficMirrorSessions(ctx context.Context, client NetworkingAPI, input *ec2.DescribeTrafficMirrorSessionsInput) iter.Seq2[types.TrafficMirrorSession, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeTrafficMirrorSessionsOutput, *ec2.Options] {
+		return ec2.NewDescribeTrafficMirrorSessionsPaginator(client, input)
+	}, func(page *ec2.DescribeTrafficMirrorSessionsOutput) []types.TrafficMirrorSession {
+		return page.TrafficMirrorSessions
+	})
+}
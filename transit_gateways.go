@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"iter"
+	"slices"
+)
+
+var transitGatewayComponent = &proto.Component{
+	Identifier:  "common-components/amazon-transit-gateway",
+	Type:        "service",
+	Title:       "Amazon VPC Transit Gateways",
+	Description: "Amazon VPC Transit Gateways are regional network hubs interconnecting VPCs, VPN connections, Direct Connect gateways and peered transit gateways. Attachment acceptance and default route table behaviour determine which networks can reach each other through the hub.",
+	Purpose:     "To centralise and control routing between networks in a hub-and-spoke topology, ensuring only approved networks are attached and that segmentation between them is enforced.",
+}
+
+// transitGateway is the policy input for a transit gateway: the gateway as returned by the EC2 API,
+// with its attachments alongside.
+type transitGateway struct {
+	types.TransitGateway
+	Attachments []types.TransitGatewayAttachment
+}
+
+func (l *CompliancePlugin) evalTransitGateways(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	// Attachments are fetched once and grouped by the transit gateway they belong to. Gateways are not
+	// evaluated without them, as a missing attachment list would read as a gateway with no attachments.
+	attachmentsByGateway := map[string][]types.TransitGatewayAttachment{}
+	for attachment, err := range getTransitGatewayAttachments(ctx, scan.client, &ec2.DescribeTransitGatewayAttachmentsInput{}) {
+		if err != nil {
+			l.logger.Error("unable to get transit gateway attachments", "region", scan.region, "error", err)
+			return err
+		}
+		gatewayID := aws.ToString(attachment.TransitGatewayId)
+		attachmentsByGateway[gatewayID] = append(attachmentsByGateway[gatewayID], attachment)
+	}
+
+	found := 0
+	for gateway, err := range getTransitGateways(ctx, scan.client, &ec2.DescribeTransitGatewaysInput{}) {
+		if err != nil {
+			l.logger.Error("unable to get transit gateway", "region", scan.region, "error", err)
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+			continue
+		}
+		if l.excludesState(scan, "transit-gateways", "transit-gateway", string(gateway.State)) {
+			continue
+		}
+		found++
+
+		gatewayID := aws.ToString(gateway.TransitGatewayId)
+		data := transitGateway{
+			TransitGateway: gateway,
+			Attachments:    attachmentsByGateway[gatewayID],
+		}
+		options := gateway.Options
+		if options == nil {
+			options = &types.TransitGatewayOptions{}
+		}
+
+		labels := internal.MergeMaps(scan.labels, l.tagLabels(gateway.Tags), map[string]string{
+			"type":               "transit-gateway",
+			"transit-gateway-id": gatewayID,
+		})
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-transit-gateway/%s", gatewayID),
+			Type:       "network",
+			Title:      fmt.Sprintf("Amazon Transit Gateway [%s]", gatewayID),
+			Props: slices.Concat([]*proto.Property{
+				{
+					Name:  "transit-gateway-id",
+					Value: gatewayID,
+				},
+				{
+					Name:  "owner-id",
+					Value: aws.ToString(gateway.OwnerId),
+				},
+				{
+					Name:  "state",
+					Value: string(gateway.State),
+				},
+				{
+					Name:  "auto-accept-shared-attachments",
+					Value: string(options.AutoAcceptSharedAttachments),
+				},
+				{
+					Name:  "default-route-table-association",
+					Value: string(options.DefaultRouteTableAssociation),
+				},
+				{
+					Name:  "default-route-table-propagation",
+					Value: string(options.DefaultRouteTablePropagation),
+				},
+			}, transitGatewayAttachmentProperties(data.Attachments), tagProperties(gateway.Tags)),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, transitGatewayComponent, inventory, collectionActivities("transit gateway", "DescribeTransitGateways"), data); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	if found == 0 && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "transit-gateway", "transit gateways", collectionActivities("transit gateway", "DescribeTransitGateways"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+func transitGatewayAttachmentProperties(attachments []types.TransitGatewayAttachment) []*proto.Property {
+	props := make([]*proto.Property, 0)
+	for i, attachment := range attachments {
+		prefix := fmt.Sprintf("attachment/%d", i)
+		props = append(props,
+			&proto.Property{Name: prefix + "/id", Value: aws.ToString(attachment.TransitGatewayAttachmentId)},
+			&proto.Property{Name: prefix + "/resource-type", Value: string(attachment.ResourceType)},
+			&proto.Property{Name: prefix + "/resource-id", Value: aws.ToString(attachment.ResourceId)},
+			&proto.Property{Name: prefix + "/resource-owner-id", Value: aws.ToString(attachment.ResourceOwnerId)},
+			&proto.Property{Name: prefix + "/state", Value: string(attachment.State)},
+		)
+	}
+	return props
+}
+
+func getTransitGateways(ctx context.Context, client NetworkingAPI, input *ec2.DescribeTransitGatewaysInput) iter.Seq2[types.TransitGateway, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeTransitGatewaysOutput, *ec2.Options] {
+		return ec2.NewDescribeTransitGatewaysPaginator(client, input)
+	}, func(page *ec2.DescribeTransitGatewaysOutput) []types.TransitGateway {
+		return page.TransitGateways
+	})
+}
+
+func getTransitGatewayAttachments(ctx context.Context, client NetworkingAPI, input *ec2.DescribeTransitGatewayAttachmentsInput) iter.Seq2[types.TransitGatewayAttachment, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeTransitGatewayAttachmentsOutput, *ec2.Options] {
+		return ec2.NewDescribeTransitGatewayAttachmentsPaginator(client, input)
+	}, func(page *ec2.DescribeTransitGatewayAttachmentsOutput) []types.TransitGatewayAttachment {
+		return page.TransitGatewayAttachments
+	})
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"github.com/compliance-framework/agent/runner/proto"
+	"runtime/debug"
+)
+
+// version and commit are set at build time with -ldflags "-X main.version=... -X main.commit=...",
+// which goreleaser does by default.
+var (
+	version = ""
+	commit  = ""
+)
+
+// pluginVersion returns the version of the running build. Builds without ldflags, e.g. via go install,
+// fall back to the module version and VCS revision recorded by the Go toolchain.
+func pluginVersion() (string, string) {
+	resolvedVersion, resolvedCommit := version, commit
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if resolvedVersion == "" && info.Main.Version != "(devel)" {
+			resolvedVersion = info.Main.Version
+		}
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" && resolvedCommit == "" {
+				resolvedCommit = setting.Value
+			}
+		}
+	}
+	if resolvedVersion == "" {
+		resolvedVersion = "dev"
+	}
+	return resolvedVersion, resolvedCommit
+}
+
+// versionProperties describes the plugin build in evidence, as `version` and, when known, `commit`.
+func versionProperties() []*proto.Property {
+	resolvedVersion, resolvedCommit := pluginVersion()
+	props := []*proto.Property{
+		{
+			Name:  "version",
+			Value: resolvedVersion,
+		},
+	}
+	if resolvedCommit != "" {
+		props = append(props, &proto.Property{
+			Name:  "commit",
+			Value: resolvedCommit,
+		})
+	}
+	return props
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// anyPrincipal is the allowed principal that lets every AWS account connect to an endpoint service.
+const anyPrincipal = "*"
+
+var vpcEndpointServiceComponent = &proto.Component{
+	Identifier:  "common-components/aws-privatelink-endpoint-service",
+	Type:        "service",
+	Title:       "AWS PrivateLink Endpoint Services",
+	Description: "AWS PrivateLink endpoint services expose a service running behind a Network or Gateway Load Balancer to interface endpoints in other VPCs and accounts. The service provider controls which principals may create endpoints and whether each connection must be accepted.",
+	Purpose:     "To ensure that only approved consumers can connect to services shared over PrivateLink, and that new connections are reviewed before traffic is allowed.",
+}
+
+// vpcEndpointService is the policy input for an endpoint service: its configuration as returned by the
+// EC2 API, with the principals allowed to connect to it.
+type vpcEndpointService struct {
+	types.ServiceConfiguration
+	AllowedPrincipals []types.AllowedPrincipal
+}
+
+func (l *CompliancePlugin) evalVpcEndpointServices(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	found := 0
+	for service, err := range getVpcEndpointServiceConfigurations(ctx, scan.client, &ec2.DescribeVpcEndpointServiceConfigurationsInput{}) {
+		if err != nil {
+			l.logger.Error("unable to get VPC endpoint service configuration", "region", scan.region, "error", err)
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+			continue
+		}
+
+		serviceID := aws.ToString(service.ServiceId)
+		data := vpcEndpointService{
+			ServiceConfiguration: service,
+			AllowedPrincipals:    make([]types.AllowedPrincipal, 0),
+		}
+		// A service is not evaluated with an incomplete list of principals, which could hide a `*`.
+		var permissionsErr error
+		permissionsInput := &ec2.DescribeVpcEndpointServicePermissionsInput{
+			ServiceId: service.ServiceId,
+		}
+		for principal, err := range getVpcEndpointServicePermissions(ctx, scan.client, permissionsInput) {
+			if err != nil {
+				permissionsErr = err
+				break
+			}
+			data.AllowedPrincipals = append(data.AllowedPrincipals, principal)
+		}
+		if permissionsErr != nil {
+			l.logger.Error("unable to get VPC endpoint service permissions", "region", scan.region, "service-id", serviceID, "error", permissionsErr)
+			accumulatedErrors = errors.Join(accumulatedErrors, permissionsErr)
+			continue
+		}
+		found++
+
+		labels := internal.MergeMaps(scan.labels, l.tagLabels(service.Tags), map[string]string{
+			"type":         "vpc-endpoint-service",
+			"service-id":   serviceID,
+			"service-name": aws.ToString(service.ServiceName),
+		})
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-vpc-endpoint-service/%s", serviceID),
+			Type:       "network",
+			Title:      fmt.Sprintf("AWS PrivateLink Endpoint Service [%s]", serviceID),
+			Props: slices.Concat([]*proto.Property{
+				{
+					Name:  "service-id",
+					Value: serviceID,
+				},
+				{
+					Name:  "service-name",
+					Value: aws.ToString(service.ServiceName),
+				},
+				{
+					Name:  "service-state",
+					Value: string(service.ServiceState),
+				},
+				{
+					Name:  "acceptance-required",
+					Value: strconv.FormatBool(aws.ToBool(service.AcceptanceRequired)),
+				},
+				{
+					Name:  "network-load-balancer-arns",
+					Value: strings.Join(service.NetworkLoadBalancerArns, ","),
+				},
+				{
+					Name:  "gateway-load-balancer-arns",
+					Value: strings.Join(service.GatewayLoadBalancerArns, ","),
+				},
+				{
+					Name:  "private-dns-name",
+					Value: aws.ToString(service.PrivateDnsName),
+				},
+			}, allowedPrincipalProperties(data.AllowedPrincipals), tagProperties(service.Tags)),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, vpcEndpointServiceComponent, inventory, collectionActivities("VPC endpoint service", "DescribeVpcEndpointServiceConfigurations"), data); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	if found == 0 && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "vpc-endpoint-service", "VPC endpoint services", collectionActivities("VPC endpoint service", "DescribeVpcEndpointServiceConfigurations"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+// allowedPrincipalProperties reports the principals allowed to connect to a service as a sorted,
+// comma-separated `allowed-principals`, with `allows-any-principal` set when one of them is `*`.
+func allowedPrincipalProperties(principals []types.AllowedPrincipal) []*proto.Property {
+	arns := make([]string, 0, len(principals))
+	for _, principal := range principals {
+		arns = append(arns, aws.ToString(principal.Principal))
+	}
+	slices.Sort(arns)
+	return []*proto.Property{
+		{
+			Name:  "allowed-principals",
+			Value: strings.Join(arns, ","),
+		},
+		{
+			Name:  "allowed-principal-count",
+			Value: strconv.Itoa(len(arns)),
+		},
+		{
+			Name:  "allows-any-principal",
+			Value: strconv.FormatBool(slices.Contains(arns, anyPrincipal)),
+		},
+	}
+}
+
+func getVpcEndpointServiceConfigurations(ctx context.Context, client NetworkingAPI, input *ec2.DescribeVpcEndpointServiceConfigurationsInput) iter.Seq2[types.ServiceConfiguration, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeVpcEndpointServiceConfigurationsOutput, *ec2.Options] {
+		return ec2.NewDescribeVpcEndpointServiceConfigurationsPaginator(client, input)
+	}, func(page *ec2.DescribeVpcEndpointServiceConfigurationsOutput) []types.ServiceConfiguration {
+		return page.ServiceConfigurations
+	})
+}
+
+func getVpcEndpointServicePermissions(ctx context.Context, client NetworkingAPI, input *ec2.DescribeVpcEndpointServicePermissionsInput) iter.Seq2[types.AllowedPrincipal, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeVpcEndpointServicePermissionsOutput, *ec2.Options] {
+		return ec2.NewDescribeVpcEndpointServicePermissionsPaginator(client, input)
+	}, func(page *ec2.DescribeVpcEndpointServicePermissionsOutput) []types.AllowedPrincipal {
+		return page.AllowedPrincipals
+	})
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"iter"
+	"slices"
+	"strconv"
+)
+
+var vpcEndpointComponent = &proto.Component{
+	Identifier:  "common-components/amazon-vpc-endpoint",
+	Type:        "service",
+	Title:       "Amazon VPC Endpoints",
+	Description: "Amazon VPC endpoints give resources in a VPC private access to AWS and partner services without traversing the internet. Gateway endpoints are added to route tables, interface endpoints place network interfaces in subnets, and both can be restricted by an endpoint policy.",
+	Purpose:     "To keep traffic to supported services on the AWS network and to limit, through endpoint policies, which principals and resources can be reached over those private paths.",
+}
+
+func (l *CompliancePlugin) evalVpcEndpoints(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	input := &ec2.DescribeVpcEndpointsInput{
+		Filters: l.vpcFilters(),
+	}
+
+	found := 0
+	for endpoint, err := range getVpcEndpoints(ctx, scan.client, input) {
+		if err != nil {
+			l.logger.Error("unable to get VPC endpoint", "region", scan.region, "error", err)
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+			continue
+		}
+		if scan.excludesVpc(aws.ToString(endpoint.VpcId)) {
+			continue
+		}
+		if l.excludesState(scan, "vpc-endpoints", "vpc-endpoint", string(endpoint.State)) {
+			continue
+		}
+		found++
+
+		endpointID := aws.ToString(endpoint.VpcEndpointId)
+
+		labels := internal.MergeMaps(scan.labels, l.tagLabels(endpoint.Tags), map[string]string{
+			"type":              "vpc-endpoint",
+			"vpc-endpoint-id":   endpointID,
+			"service-name":      aws.ToString(endpoint.ServiceName),
+			"vpc-endpoint-type": string(endpoint.VpcEndpointType),
+			"_vpc-id":           aws.ToString(endpoint.VpcId),
+		})
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-vpc-endpoint/%s", endpointID),
+			Type:       "network",
+			Title:      fmt.Sprintf("Amazon VPC Endpoint [%s]", endpointID),
+			Props: slices.Concat([]*proto.Property{
+				{
+					Name:  "vpc-endpoint-id",
+					Value: endpointID,
+				},
+				{
+					Name:  "vpc-id",
+					Value: aws.ToString(endpoint.VpcId),
+				},
+				{
+					Name:  "service-name",
+					Value: aws.ToString(endpoint.ServiceName),
+				},
+				{
+					Name:  "vpc-endpoint-type",
+					Value: string(endpoint.VpcEndpointType),
+				},
+				{
+					Name:  "state",
+					Value: string(endpoint.State),
+				},
+				{
+					Name:  "private-dns-enabled",
+					Value: strconv.FormatBool(aws.ToBool(endpoint.PrivateDnsEnabled)),
+				},
+				{
+					Name:  "policy-document",
+					Value: aws.ToString(endpoint.PolicyDocument),
+				},
+			}, tagProperties(endpoint.Tags)),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, vpcEndpointComponent, inventory, collectionActivities("VPC endpoint", "DescribeVpcEndpoints"), endpoint); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	if found == 0 && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "vpc-endpoint", "VPC endpoints", collectionActivities("VPC endpoint", "DescribeVpcEndpoints"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+func getVpcEndpoints(ctx context.Context, client NetworkingAPI, input *ec2.DescribeVpcEndpointsInput) iter.Seq2[types.VpcEndpoint, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeVpcEndpointsOutput, *ec2.Options] {
+		return ec2.NewDescribeVpcEndpointsPaginator(client, input)
+	}, func(page *ec2.DescribeVpcEndpointsOutput) []types.VpcEndpoint {
+		return page.VpcEndpoints
+	})
+}
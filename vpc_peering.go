@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"iter"
+	"slices"
+	"strconv"
+)
+
+var vpcPeeringComponent = &proto.Component{
+	Identifier:  "common-components/amazon-vpc-peering-connection",
+	Type:        "service",
+	Title:       "Amazon VPC Peering Connections",
+	Description: "Amazon VPC peering connections route private traffic directly between two VPCs, which may belong to different accounts or regions. Each side can additionally allow resolution of the other VPC's private DNS names.",
+	Purpose:     "To make every trust relationship between networks explicit, so that connectivity into other VPCs and accounts can be reviewed and restricted to approved peers.",
+}
+
+func (l *CompliancePlugin) evalVpcPeeringConnections(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	found := 0
+	for peering, err := range getVpcPeeringConnections(ctx, scan.client, &ec2.DescribeVpcPeeringConnectionsInput{}) {
+		if err != nil {
+			l.logger.Error("unable to get VPC peering connection", "region", scan.region, "error", err)
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+			continue
+		}
+
+		requester := peering.RequesterVpcInfo
+		if requester == nil {
+			requester = &types.VpcPeeringConnectionVpcInfo{}
+		}
+		accepter := peering.AccepterVpcInfo
+		if accepter == nil {
+			accepter = &types.VpcPeeringConnectionVpcInfo{}
+		}
+
+		// EC2 filters on different names are ANDed, so a connection is scoped to vpc_ids here when
+		// either of its sides is in scope.
+		if len(l.config.VpcIDs) > 0 && !slices.Contains(l.config.VpcIDs, aws.ToString(requester.VpcId)) && !slices.Contains(l.config.VpcIDs, aws.ToString(accepter.VpcId)) {
+			continue
+		}
+		if scan.excludesVpc(aws.ToString(requester.VpcId)) && scan.excludesVpc(aws.ToString(accepter.VpcId)) {
+			continue
+		}
+
+		peeringID := aws.ToString(peering.VpcPeeringConnectionId)
+		status := ""
+		if peering.Status != nil {
+			status = string(peering.Status.Code)
+		}
+		if l.excludesState(scan, "vpc-peering-connections", "vpc-peering-connection", status) {
+			continue
+		}
+		found++
+		crossAccount := aws.ToString(requester.OwnerId) != aws.ToString(accepter.OwnerId)
+
+		labels := internal.MergeMaps(scan.labels, l.tagLabels(peering.Tags), map[string]string{
+			"type":                      "vpc-peering-connection",
+			"vpc-peering-connection-id": peeringID,
+			"cross-account":             strconv.FormatBool(crossAccount),
+		})
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-vpc-peering-connection/%s", peeringID),
+			Type:       "network",
+			Title:      fmt.Sprintf("Amazon VPC Peering Connection [%s]", peeringID),
+			Props: slices.Concat([]*proto.Property{
+				{
+					Name:  "vpc-peering-connection-id",
+					Value: peeringID,
+				},
+				{
+					Name:  "status",
+					Value: status,
+				},
+				{
+					Name:  "cross-account",
+					Value: strconv.FormatBool(crossAccount),
+				},
+			}, peeringSideProperties("requester", requester), peeringSideProperties("accepter", accepter), tagProperties(peering.Tags)),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, vpcPeeringComponent, inventory, collectionActivities("VPC peering connection", "DescribeVpcPeeringConnections"), peering); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	if found == 0 && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "vpc-peering-connection", "VPC peering connections", collectionActivities("VPC peering connection", "DescribeVpcPeeringConnections"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+// peeringSideProperties describes one side of a peering connection as `<side>-vpc-id`,
+// `<side>-owner-id`, `<side>-region`, `<side>-cidr` and `<side>-allow-remote-dns-resolution`. The DNS
+// option is only reported once AWS returns peering options, i.e. when the connection is active.
+func peeringSideProperties(side string, info *types.VpcPeeringConnectionVpcInfo) []*proto.Property {
+	props := []*proto.Property{
+		{
+			Name:  side + "-vpc-id",
+			Value: aws.ToString(info.VpcId),
+		},
+		{
+			Name:  side + "-owner-id",
+			Value: aws.ToString(info.OwnerId),
+		},
+		{
+			Name:  side + "-region",
+			Value: aws.ToString(info.Region),
+		},
+		{
+			Name:  side + "-cidr",
+			Value: aws.ToString(info.CidrBlock),
+		},
+	}
+	if info.PeeringOptions != nil {
+		props = append(props, &proto.Property{
+			Name:  side + "-allow-remote-dns-resolution",
+			Value: strconv.FormatBool(aws.ToBool(info.PeeringOptions.AllowDnsResolutionFromRemoteVpc)),
+		})
+	}
+	return props
+}
+
+func getVpcPeeringConnections(ctx context.Context, client NetworkingAPI, input *ec2.DescribeVpcPeeringConnectionsInput) iter.Seq2[types.VpcPeeringConnection, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeVpcPeeringConnectionsOutput, *ec2.Options] {
+		return ec2.NewDescribeVpcPeeringConnectionsPaginator(client, input)
+	}, func(page *ec2.DescribeVpcPeeringConnectionsOutput) []types.VpcPeeringConnection {
+		return page.VpcPeeringConnections
+	})
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner/proto"
+	"iter"
+	"strconv"
+)
+
+// loadVpcs describes the in-scope VPCs of the region once and caches them on the scan. With
+// skip_default_vpc, the default VPC is recorded as excluded instead, unless it is listed in vpc_ids.
+func (l *CompliancePlugin) loadVpcs(ctx context.Context, scan *regionScan) error {
+	input := &ec2.DescribeVpcsInput{
+		Filters: l.vpcFilters(),
+	}
+
+	vpcs := map[string]types.Vpc{}
+	for vpc, err := range getVpcs(ctx, scan.client, input) {
+		if err != nil {
+			l.logger.Error("unable to get VPC", "region", scan.region, "error", err)
+			return err
+		}
+		if l.config.SkipDefaultVpc && len(l.config.VpcIDs) == 0 && aws.ToBool(vpc.IsDefault) {
+			scan.excludedVpcs[aws.ToString(vpc.VpcId)] = true
+			continue
+		}
+		vpcs[aws.ToString(vpc.VpcId)] = vpc
+	}
+	scan.vpcs = vpcs
+	return nil
+}
+
+// vpcProperties enriches a resource's evidence with the context of the VPC it lives in, as
+// `vpc-cidr`, `vpc-is-default`, `vpc-instance-tenancy`, `vpc-dhcp-options-id`, `vpc-internet-egress`
+// and `vpc-tag/<key>` properties. Nothing is returned when the VPC is unknown.
+func vpcProperties(scan *regionScan, vpcID string) []*proto.Property {
+	vpc, ok := scan.vpcs[vpcID]
+	if !ok {
+		return nil
+	}
+
+	props := []*proto.Property{
+		{
+			Name:  "vpc-cidr",
+			Value: aws.ToString(vpc.CidrBlock),
+		},
+		{
+			Name:  "vpc-is-default",
+			Value: strconv.FormatBool(aws.ToBool(vpc.IsDefault)),
+		},
+		{
+			Name:  "vpc-instance-tenancy",
+			Value: string(vpc.InstanceTenancy),
+		},
+		{
+			Name:  "vpc-dhcp-options-id",
+			Value: aws.ToString(vpc.DhcpOptionsId),
+		},
+	}
+	if scan.internetGatewayVpcs != nil {
+		props = append(props, &proto.Property{
+			Name:  "vpc-internet-egress",
+			Value: strconv.FormatBool(len(scan.internetGatewayVpcs[vpcID]) > 0),
+		})
+	}
+	for _, tag := range vpc.Tags {
+		props = append(props, &proto.Property{
+			Name:  "vpc-tag/" + aws.ToString(tag.Key),
+			Value: aws.ToString(tag.Value),
+		})
+	}
+	return props
+}
+
+func getVpcs(ctx context.Context, client NetworkingAPI, input *ec2.DescribeVpcsInput) iter.Seq2[types.Vpc, error] {
+	return paginate(ctx, func() pager[*ec2.DescribeVpcsOutput, *ec2.Options] {
+		return ec2.NewDescribeVpcsPaginator(client, input)
+	}, func(page *ec2.DescribeVpcsOutput) []types.Vpc {
+		return page.Vpcs
+	})
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/compliance-framework/agent/runner"
+	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-aws-networking-security/internal"
+	"slices"
+	"strconv"
+)
+
+var vpnConnectionComponent = &proto.Component{
+	Identifier:  "common-components/aws-site-to-site-vpn",
+	Type:        "service",
+	Title:       "AWS Site-to-Site VPN Connections",
+	Description: "AWS Site-to-Site VPN connections link on-premises networks to a virtual private gateway or transit gateway over two redundant IPsec tunnels, routed either statically or with BGP.",
+	Purpose:     "To provide encrypted, resilient hybrid connectivity, with both tunnels available so a single tunnel failure does not interrupt connectivity.",
+}
+
+func (l *CompliancePlugin) evalVpnConnections(ctx context.Context, scan *regionScan, request *proto.EvalRequest, apiHelper runner.ApiHelper) error {
+	var accumulatedErrors error
+
+	connections, err := getVpnConnections(ctx, scan.client, &ec2.DescribeVpnConnectionsInput{})
+	if err != nil {
+		l.logger.Error("unable to get VPN connections", "region", scan.region, "error", err)
+		return err
+	}
+
+	found := 0
+	for _, connection := range connections {
+		if l.excludesState(scan, "vpn-connections", "vpn-connection", string(connection.State)) {
+			continue
+		}
+		found++
+		connectionID := aws.ToString(connection.VpnConnectionId)
+		staticRoutesOnly := connection.Options != nil && aws.ToBool(connection.Options.StaticRoutesOnly)
+		routing := "bgp"
+		if staticRoutesOnly {
+			routing = "static"
+		}
+		allTunnelsUp := len(connection.VgwTelemetry) > 0 && !slices.ContainsFunc(connection.VgwTelemetry, func(tunnel types.VgwTelemetry) bool {
+			return tunnel.Status != types.TelemetryStatusUp
+		})
+
+		labels := internal.MergeMaps(scan.labels, l.tagLabels(connection.Tags), map[string]string{
+			"type":              "vpn-connection",
+			"vpn-connection-id": connectionID,
+		})
+
+		inventory := &proto.InventoryItem{
+			Identifier: fmt.Sprintf("aws-vpn-connection/%s", connectionID),
+			Type:       "network",
+			Title:      fmt.Sprintf("AWS Site-to-Site VPN Connection [%s]", connectionID),
+			Props: slices.Concat([]*proto.Property{
+				{
+					Name:  "vpn-connection-id",
+					Value: connectionID,
+				},
+				{
+					Name:  "state",
+					Value: string(connection.State),
+				},
+				{
+					Name:  "type",
+					Value: string(connection.Type),
+				},
+				{
+					Name:  "customer-gateway-id",
+					Value: aws.ToString(connection.CustomerGatewayId),
+				},
+				{
+					Name:  "vpn-gateway-id",
+					Value: aws.ToString(connection.VpnGatewayId),
+				},
+				{
+					Name:  "transit-gateway-id",
+					Value: aws.ToString(connection.TransitGatewayId),
+				},
+				{
+					Name:  "routing",
+					Value: routing,
+				},
+				{
+					Name:  "all-tunnels-up",
+					Value: strconv.FormatBool(allTunnelsUp),
+				},
+			}, tunnelProperties(connection.VgwTelemetry), tagProperties(connection.Tags)),
+		}
+
+		if err := l.evaluateResource(ctx, request, apiHelper, scan, labels, vpnConnectionComponent, inventory, collectionActivities("VPN connection", "DescribeVpnConnections"), connection); err != nil {
+			accumulatedErrors = errors.Join(accumulatedErrors, err)
+		}
+	}
+
+	if found == 0 && accumulatedErrors == nil {
+		accumulatedErrors = l.reportNoResources(ctx, scan, "vpn-connection", "VPN connections", collectionActivities("VPN connection", "DescribeVpnConnections"), apiHelper)
+	}
+
+	return accumulatedErrors
+}
+
+func tunnelProperties(tunnels []types.VgwTelemetry) []*proto.Property {
+	props := make([]*proto.Property, 0)
+	for i, tunnel := range tunnels {
+		prefix := fmt.Sprintf("tunnel/%d", i)
+		props = append(props,
+			&proto.Property{Name: prefix + "/outside-ip", Value: aws.ToString(tunnel.OutsideIpAddress)},
+			&proto.Property{Name: prefix + "/status", Value: string(tunnel.Status)},
+			&proto.Property{Name: prefix + "/status-message", Value: aws.ToString(tunnel.StatusMessage)},
+		)
+	}
+	return props
+}
+
+// getVpnConnections returns every Site-to-Site VPN connection in the region. DescribeVpnConnections is
+// not paginated.
+func getVpnConnections(ctx context.Context, client NetworkingAPI, input *ec2.DescribeVpnConnectionsInput) ([]types.VpnConnection, error) {
+	result, err := client.DescribeVpnConnections(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return result.VpnConnections, nil
+}